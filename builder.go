@@ -0,0 +1,475 @@
+package main
+
+import (
+        "fmt"
+        "sort"
+        "strconv"
+        "strings"
+
+        "github.com/charmbracelet/bubbletea"
+        "github.com/charmbracelet/lipgloss"
+)
+
+// builderMode is how a single field of the schedule builder is specified
+type builderMode int
+
+const (
+        builderEvery builderMode = iota // *
+        builderStep                     // */n
+        builderList                     // Checked values
+        builderRange                    // a-b, optionally with a step
+)
+
+var builderModeNames = []string{"Every", "Step", "List", "Range"}
+
+// builderField holds the picker state for one cron field
+type builderField struct {
+        Label    string
+        Unit     string // Plural noun used in "every n ..." text
+        Bounds   fieldBounds
+        PerRow   int // Values per row in the checkbox grid
+        Mode     builderMode
+        Selected map[int]bool
+        Cursor   int // Value under the cursor in list mode
+        Start    int
+        End      int
+        Step     int
+        Control  int // 0 = start, 1 = end, 2 = step in range mode
+        Original string // Field text the pickers can't show, kept until the field is changed
+}
+
+// scheduleBuilder is the form-style alternative to typing a cron expression
+type scheduleBuilder struct {
+        Fields [5]builderField
+        Active int
+}
+
+// builderPreset is a ready-made schedule selectable with a number key
+type builderPreset struct {
+        Name       string
+        Expression string
+}
+
+var builderPresets = []builderPreset{
+        {Name: "Every minute", Expression: "* * * * *"},
+        {Name: "Every 15 minutes", Expression: "*/15 * * * *"},
+        {Name: "Hourly", Expression: "0 * * * *"},
+        {Name: "Daily at midnight", Expression: "0 0 * * *"},
+        {Name: "Weekdays at 9:00", Expression: "0 9 * * 1-5"},
+        {Name: "Weekly on Sunday", Expression: "0 0 * * 0"},
+        {Name: "Monthly on the 1st", Expression: "0 0 1 * *"},
+        {Name: "Yearly on Jan 1", Expression: "0 0 1 1 *"},
+}
+
+var (
+        builderActiveStyle = lipgloss.NewStyle().
+                Foreground(lipgloss.Color("229")).
+                Background(lipgloss.Color("57")).
+                Padding(0, 1)
+
+        builderTabStyle = lipgloss.NewStyle().
+                Foreground(lipgloss.Color("244")).
+                Padding(0, 1)
+)
+
+// newScheduleBuilder creates a builder, loading expr into it when possible
+func newScheduleBuilder(expr string) scheduleBuilder {
+        // Day of week is shown as 0-6 in the builder, Sunday first
+        weekBounds := dowBounds
+        weekBounds.Max = 6
+
+        b := scheduleBuilder{Fields: [5]builderField{
+                {Label: "Minute", Unit: "minutes", Bounds: minuteBounds, PerRow: 10},
+                {Label: "Hour", Unit: "hours", Bounds: hourBounds, PerRow: 12},
+                {Label: "Day", Unit: "days", Bounds: domBounds, PerRow: 10},
+                {Label: "Month", Unit: "months", Bounds: monthBounds, PerRow: 6},
+                {Label: "Weekday", Unit: "days of the week", Bounds: weekBounds, PerRow: 7},
+        }}
+        for i := range b.Fields {
+                b.Fields[i].reset()
+        }
+        b.load(expr)
+        return b
+}
+
+// load sets the builder fields from a cron expression. Fields the pickers
+// can't represent (like mixed lists of ranges) keep their original text until
+// they are changed, so using the builder doesn't lose them.
+func (b *scheduleBuilder) load(expr string) {
+        expr = strings.TrimSpace(expr)
+        if expanded, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+                expr = expanded
+        }
+        // The weekday pickers stop at Saturday, so Sunday has to be 0
+        expr = normalizeSunday(expr)
+        ast, err := parseCronAST(expr)
+        if err != nil {
+                return
+        }
+        parts := strings.Fields(expr)
+
+        fields := []cronField{ast.Minute, ast.Hour, ast.DayOfMonth, ast.Month, ast.DayOfWeek}
+        for i, field := range fields {
+                f := &b.Fields[i]
+                f.reset()
+
+                if values, ok := field.Singles(); ok {
+                        f.Mode = builderList
+                        for _, v := range values {
+                                f.Selected[v%(f.Bounds.Max+1)] = true
+                        }
+                        continue
+                }
+
+                r, ok := field.single()
+                switch {
+                case !ok:
+                        f.Original = parts[i]
+                case r.Every && r.Step <= 1:
+                        f.Mode = builderEvery
+                case r.Every:
+                        f.Mode = builderStep
+                        f.Step = r.Step
+                default:
+                        f.Mode = builderRange
+                        f.Start, f.End = r.Start, r.End
+                        if f.End > f.Bounds.Max {
+                                f.End = f.Bounds.Max
+                        }
+                        if r.Step > 0 {
+                                f.Step = r.Step
+                        }
+                }
+        }
+}
+
+// reset puts a field back to "Every" with sensible range and step defaults
+func (f *builderField) reset() {
+        f.Mode = builderEvery
+        f.Selected = map[int]bool{}
+        f.Cursor = f.Bounds.Min
+        f.Start = f.Bounds.Min
+        f.End = f.Bounds.Max
+        f.Step = 1
+        f.Control = 0
+        f.Original = ""
+}
+
+// Expression returns the cron text for the field
+func (f builderField) Expression() string {
+        if f.Original != "" {
+                return f.Original
+        }
+        switch f.Mode {
+        case builderStep:
+                if f.Step <= 1 {
+                        return "*"
+                }
+                return fmt.Sprintf("*/%d", f.Step)
+
+        case builderList:
+                var values []int
+                for v, on := range f.Selected {
+                        if on {
+                                values = append(values, v)
+                        }
+                }
+                if len(values) == 0 {
+                        return "*"
+                }
+                sort.Ints(values)
+                return compressValues(values)
+
+        case builderRange:
+                text := strconv.Itoa(f.Start)
+                if f.End != f.Start {
+                        text += "-" + strconv.Itoa(f.End)
+                }
+                if f.Step > 1 {
+                        text += "/" + strconv.Itoa(f.Step)
+                }
+                return text
+        }
+        return "*"
+}
+
+// Expression returns the full five-field cron expression
+func (b scheduleBuilder) Expression() string {
+        parts := make([]string, len(b.Fields))
+        for i, f := range b.Fields {
+                parts[i] = f.Expression()
+        }
+        return strings.Join(parts, " ")
+}
+
+// compressValues writes sorted values as a cron list, turning runs of three
+// or more consecutive values into ranges
+func compressValues(values []int) string {
+        var items []string
+        for i := 0; i < len(values); {
+                j := i
+                for j+1 < len(values) && values[j+1] == values[j]+1 {
+                        j++
+                }
+                if j-i >= 2 {
+                        items = append(items, fmt.Sprintf("%d-%d", values[i], values[j]))
+                } else {
+                        for k := i; k <= j; k++ {
+                                items = append(items, strconv.Itoa(values[k]))
+                        }
+                }
+                i = j + 1
+        }
+        return strings.Join(items, ",")
+}
+
+// valueLabel returns the label shown for a value in the field's pickers
+func (f builderField) valueLabel(v int) string {
+        switch f.Label {
+        case "Month":
+                return monthName(v)[:3]
+        case "Weekday":
+                return dayName(v)[:3]
+        case "Day":
+                return fmt.Sprintf("%2d", v)
+        }
+        return fmt.Sprintf("%02d", v)
+}
+
+// clamp keeps v within the field's bounds
+func (f builderField) clamp(v int) int {
+        if v < f.Bounds.Min {
+                return f.Bounds.Min
+        }
+        if v > f.Bounds.Max {
+                return f.Bounds.Max
+        }
+        return v
+}
+
+// adjust moves the active control of the field by delta
+func (f *builderField) adjust(delta int) {
+        switch f.Mode {
+        case builderStep:
+                f.Step += delta
+                if f.Step < 1 {
+                        f.Step = 1
+                }
+                if f.Step > f.Bounds.Max {
+                        f.Step = f.Bounds.Max
+                }
+
+        case builderList:
+                f.Cursor = f.clamp(f.Cursor + delta)
+
+        case builderRange:
+                switch f.Control {
+                case 0:
+                        f.Start = f.clamp(f.Start + delta)
+                        if f.Start > f.End {
+                                f.End = f.Start
+                        }
+                case 1:
+                        f.End = f.clamp(f.End + delta)
+                        if f.End < f.Start {
+                                f.Start = f.End
+                        }
+                case 2:
+                        f.Step += delta
+                        if f.Step < 1 {
+                                f.Step = 1
+                        }
+                }
+        }
+}
+
+// openBuilder switches to the schedule builder, starting from the expression
+// currently in the edit form
+func (m Model) openBuilder() (tea.Model, tea.Cmd) {
+        m.builder = newScheduleBuilder(m.inputs[1].Value())
+        m.mode = ViewBuilder
+        return m, nil
+}
+
+// updateBuilder handles key presses in the schedule builder
+func (m Model) updateBuilder(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+        b := &m.builder
+        f := &b.Fields[b.Active]
+
+        switch key := msg.String(); key {
+        case "esc", "ctrl+c":
+                m.mode = ViewEdit
+                return m, nil
+
+        case "enter":
+                m.inputs[1].SetValue(b.Expression())
+                m.inputs[1].CursorEnd()
+                m.mode = ViewEdit
+                return m, nil
+
+        case "tab":
+                b.Active = (b.Active + 1) % len(b.Fields)
+
+        case "shift+tab":
+                b.Active = (b.Active + len(b.Fields) - 1) % len(b.Fields)
+
+        case "m":
+                f.Mode = (f.Mode + 1) % builderMode(len(builderModeNames))
+                f.Control = 0
+                f.Original = ""
+
+        case "left", "h":
+                f.adjust(-1)
+
+        case "right", "l":
+                f.adjust(1)
+
+        case "up", "k":
+                if f.Mode == builderList {
+                        f.adjust(-f.PerRow)
+                } else if f.Mode == builderRange && f.Control > 0 {
+                        f.Control--
+                }
+
+        case "down", "j":
+                if f.Mode == builderList {
+                        f.adjust(f.PerRow)
+                } else if f.Mode == builderRange && f.Control < 2 {
+                        f.Control++
+                }
+
+        case " ", "x":
+                if f.Mode == builderList {
+                        f.Selected[f.Cursor] = !f.Selected[f.Cursor]
+                }
+
+        case "c":
+                f.reset()
+
+        default:
+                // Number keys pick a preset
+                if n, err := strconv.Atoi(key); err == nil && n >= 1 && n <= len(builderPresets) {
+                        b.load(builderPresets[n-1].Expression)
+                }
+        }
+
+        return m, nil
+}
+
+// viewBuilder renders the schedule builder
+func (m Model) viewBuilder() string {
+        var b strings.Builder
+        builder := m.builder
+        f := builder.Fields[builder.Active]
+
+        b.WriteString(titleStyle.Render("Schedule Builder"))
+        b.WriteString("\n")
+
+        // Field tabs, each showing its current cron text
+        var tabs []string
+        for i, field := range builder.Fields {
+                label := fmt.Sprintf("%s: %s", field.Label, field.Expression())
+                if i == builder.Active {
+                        tabs = append(tabs, builderActiveStyle.Render(label))
+                } else {
+                        tabs = append(tabs, builderTabStyle.Render(label))
+                }
+        }
+        b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tabs...))
+        b.WriteString("\n\n")
+
+        // Mode selector
+        var modes []string
+        for i, name := range builderModeNames {
+                if builderMode(i) == f.Mode && f.Original == "" {
+                        modes = append(modes, builderActiveStyle.Render(name))
+                } else {
+                        modes = append(modes, builderTabStyle.Render(name))
+                }
+        }
+        b.WriteString("Mode: " + strings.Join(modes, " "))
+        b.WriteString("\n\n")
+
+        // Controls for the active mode
+        control := func(label string, value string, active bool) string {
+                text := fmt.Sprintf("%s ‹ %s ›", label, value)
+                if active {
+                        return builderActiveStyle.Render(text)
+                }
+                return builderTabStyle.Render(text)
+        }
+        switch {
+        case f.Original != "":
+                b.WriteString(warningStyle.Render(fmt.Sprintf("%s can't be shown with the pickers, it is kept as is", f.Original)))
+                b.WriteString("\n")
+                b.WriteString(helpStyle.Render("Press m to pick a mode or c to clear it, either replaces it"))
+                b.WriteString("\n")
+
+        case f.Mode == builderEvery:
+                b.WriteString(fmt.Sprintf("Runs every %s", strings.TrimSuffix(f.Unit, "s")))
+                b.WriteString("\n")
+
+        case f.Mode == builderStep:
+                b.WriteString(control("Every", fmt.Sprintf("%d %s", f.Step, f.Unit), true))
+                b.WriteString("\n")
+
+        case f.Mode == builderList:
+                for v := f.Bounds.Min; v <= f.Bounds.Max; v++ {
+                        box := "[ ]"
+                        if f.Selected[v] {
+                                box = "[x]"
+                        }
+                        cell := fmt.Sprintf("%s %s", box, f.valueLabel(v))
+                        if v == f.Cursor {
+                                cell = builderActiveStyle.Render(cell)
+                        } else {
+                                cell = builderTabStyle.Render(cell)
+                        }
+                        b.WriteString(cell)
+                        if (v-f.Bounds.Min+1)%f.PerRow == 0 {
+                                b.WriteString("\n")
+                        }
+                }
+                b.WriteString("\n")
+
+        case f.Mode == builderRange:
+                b.WriteString(control("From", f.valueLabel(f.Start), f.Control == 0))
+                b.WriteString("\n")
+                b.WriteString(control("To  ", f.valueLabel(f.End), f.Control == 1))
+                b.WriteString("\n")
+                b.WriteString(control("Step", strconv.Itoa(f.Step), f.Control == 2))
+                b.WriteString("\n")
+        }
+        b.WriteString("\n")
+
+        // Live result
+        expression := builder.Expression()
+        b.WriteString(lipgloss.NewStyle().Bold(true).Render("Expression: "))
+        b.WriteString(expression)
+        b.WriteString(cronDescStyle.Render(" → " + ParseCronExpression(expression)))
+        b.WriteString("\n\n")
+
+        // Presets
+        b.WriteString(lipgloss.NewStyle().Bold(true).Render("Presets:"))
+        b.WriteString("\n")
+        for i, preset := range builderPresets {
+                b.WriteString(helpStyle.Render(fmt.Sprintf("%d: %-20s %s", i+1, preset.Name, preset.Expression)))
+                b.WriteString("\n")
+        }
+
+        // Keybindings
+        keybindings := []string{
+                "tab: next field",
+                "m: mode",
+                "←/→: value",
+                "↑/↓: row/control",
+                "space: toggle",
+                "c: clear",
+                "1-8: preset",
+                "enter: use",
+                "esc: cancel",
+        }
+        b.WriteString(keybindingStyle.Render(strings.Join(keybindings, " • ")))
+
+        return baseStyle.Render(b.String())
+}
@@ -0,0 +1,32 @@
+package main
+
+import "testing"
+
+func TestScheduleBuilderRoundTrip(t *testing.T) {
+        tests := []struct {
+                expr string
+                want string
+        }{
+                {"*/15 9-17 * * 1-5", "*/15 9-17 * * 1-5"},
+                {"0 9 * * 1,3,5", "0 9 * * 1,3,5"},
+                {"0 9-12,14-17 * * *", "0 9-12,14-17 * * *"},
+                {"0 0 1-7,15 * 1-5/2", "0 0 1-7,15 * 1-5/2"},
+                {"@daily", "0 0 * * *"},
+        }
+        for _, tt := range tests {
+                if got := newScheduleBuilder(tt.expr).Expression(); got != tt.want {
+                        t.Errorf("newScheduleBuilder(%q).Expression() = %q, want %q", tt.expr, got, tt.want)
+                }
+        }
+}
+
+func TestScheduleBuilderReplacesKeptField(t *testing.T) {
+        b := newScheduleBuilder("0 9-12,14-17 * * *")
+        if b.Fields[1].Original != "9-12,14-17" {
+                t.Fatalf("hour field kept %q, want %q", b.Fields[1].Original, "9-12,14-17")
+        }
+        b.Fields[1].reset()
+        if got := b.Expression(); got != "0 * * * *" {
+                t.Errorf("Expression() after clearing the hour = %q, want %q", got, "0 * * * *")
+        }
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+        "fmt"
+        "sort"
+        "strings"
+        "time"
+
+        "github.com/charmbracelet/bubbletea"
+        "github.com/charmbracelet/lipgloss"
+)
+
+// CalendarMode is the layout of the calendar view
+type CalendarMode int
+
+const (
+        CalendarMonth CalendarMode = iota
+        CalendarWeek
+        CalendarDay // Hourly timeline of a single day
+)
+
+// maxRunsPerJob caps how many runs are listed for one job in a range, which
+// keeps every-minute jobs from stalling the view
+const maxRunsPerJob = 50000
+
+// jobRun is a single planned execution of a job
+type jobRun struct {
+        Job  int // Index into Model.jobs
+        Time time.Time
+}
+
+var (
+        calendarSelectedStyle = lipgloss.NewStyle().
+                Foreground(lipgloss.Color("229")).
+                Background(lipgloss.Color("57")).
+                Bold(true)
+
+        calendarTodayStyle = lipgloss.NewStyle().
+                Foreground(lipgloss.Color("86")).
+                Bold(true)
+
+        calendarQuietStyle = lipgloss.NewStyle().
+                Foreground(lipgloss.Color("240"))
+)
+
+// jobName returns a short label for a job in compact views
+func jobName(job CronJob) string {
+        if job.Description != "" {
+                return job.Description
+        }
+        if job.LogFile != "" {
+                return job.LogFile
+        }
+        return truncateText(StripLoggingFromCommand(job.Command), 30)
+}
+
+// startOfDay returns midnight at the start of t's day
+func startOfDay(t time.Time) time.Time {
+        return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// runsBetween lists the runs of the given jobs in [start, end), ordered by time
+func runsBetween(jobs []CronJob, indices []int, start, end time.Time) []jobRun {
+        var runs []jobRun
+        for _, i := range indices {
+                if jobs[i].Disabled {
+                        continue
+                }
+                times, err := GetRunTimesBetween(jobs[i].Expression, start, end, maxRunsPerJob)
+                if err != nil {
+                        continue
+                }
+                for _, t := range times {
+                        runs = append(runs, jobRun{Job: i, Time: t})
+                }
+        }
+        sort.SliceStable(runs, func(a, b int) bool {
+                return runs[a].Time.Before(runs[b].Time)
+        })
+        return runs
+}
+
+// countByDay counts runs per calendar day, keyed by "2006-01-02"
+func countByDay(runs []jobRun) map[string]int {
+        counts := map[string]int{}
+        for _, run := range runs {
+                counts[run.Time.Format("2006-01-02")]++
+        }
+        return counts
+}
+
+// loadStyle colors a run count relative to the busiest day in view
+func loadStyle(count, busiest int) lipgloss.Style {
+        switch {
+        case count == 0:
+                return calendarQuietStyle
+        case count*3 >= busiest*2:
+                return errorStyle
+        case count*3 >= busiest:
+                return cronDescStyle
+        default:
+                return successStyle
+        }
+}
+
+// openCalendar switches to the calendar view on today's date
+func (m Model) openCalendar() (tea.Model, tea.Cmd) {
+        m.mode = ViewCalendar
+        m.calendarMode = CalendarMonth
+        m.calendarDate = startOfDay(time.Now())
+        return m, nil
+}
+
+// updateCalendar handles key presses in the calendar view
+func (m Model) updateCalendar(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+        switch msg.String() {
+        case "esc", "q":
+                if m.calendarMode == CalendarDay {
+                        m.calendarMode = m.calendarReturn
+                } else {
+                        m.mode = ViewTable
+                }
+                return m, nil
+
+        case "enter":
+                if m.calendarMode != CalendarDay {
+                        m.calendarReturn = m.calendarMode
+                        m.calendarMode = CalendarDay
+                }
+
+        case "w":
+                if m.calendarMode == CalendarWeek {
+                        m.calendarMode = CalendarMonth
+                } else {
+                        m.calendarMode = CalendarWeek
+                }
+
+        case "left", "h":
+                m.calendarDate = m.calendarDate.AddDate(0, 0, -1)
+        case "right", "l":
+                m.calendarDate = m.calendarDate.AddDate(0, 0, 1)
+        case "up", "k":
+                m.calendarDate = m.calendarDate.AddDate(0, 0, -7)
+        case "down", "j":
+                m.calendarDate = m.calendarDate.AddDate(0, 0, 7)
+
+        case "[":
+                if m.calendarMode == CalendarMonth {
+                        m.calendarDate = m.calendarDate.AddDate(0, -1, 0)
+                } else {
+                        m.calendarDate = m.calendarDate.AddDate(0, 0, -7)
+                }
+        case "]":
+                if m.calendarMode == CalendarMonth {
+                        m.calendarDate = m.calendarDate.AddDate(0, 1, 0)
+                } else {
+                        m.calendarDate = m.calendarDate.AddDate(0, 0, 7)
+                }
+
+        case "t":
+                m.calendarDate = startOfDay(time.Now())
+        }
+        return m, nil
+}
+
+// viewCalendar renders the calendar view
+func (m Model) viewCalendar() string {
+        var b strings.Builder
+
+        switch m.calendarMode {
+        case CalendarMonth:
+                b.WriteString(m.viewCalendarMonth())
+        case CalendarWeek:
+                b.WriteString(m.viewCalendarWeek())
+        case CalendarDay:
+                b.WriteString(m.viewCalendarDay())
+        }
+        b.WriteString("\n")
+
+        // Keybindings
+        var keybindings []string
+        if m.calendarMode == CalendarDay {
+                keybindings = []string{
+                        "←/→: previous/next day",
+                        "Esc/q: back to calendar",
+                }
+        } else {
+                keybindings = []string{
+                        "arrows: move day",
+                        "[/]: previous/next page",
+                        "w: month/week",
+                        "t: today",
+                        "enter: day timeline",
+                        "Esc/q: back to jobs",
+                }
+        }
+        b.WriteString(keybindingStyle.Render(strings.Join(keybindings, " • ")))
+
+        return baseStyle.Render(b.String())
+}
+
+// viewCalendarMonth renders a month grid with the number of runs on each day
+func (m Model) viewCalendarMonth() string {
+        var b strings.Builder
+        selected := m.calendarDate
+        first := time.Date(selected.Year(), selected.Month(), 1, 0, 0, 0, 0, selected.Location())
+        next := first.AddDate(0, 1, 0)
+
+        runs := runsBetween(m.jobs, m.visible, first, next)
+        counts := countByDay(runs)
+        busiest := 0
+        for _, count := range counts {
+                if count > busiest {
+                        busiest = count
+                }
+        }
+
+        b.WriteString(titleStyle.Render(fmt.Sprintf("Calendar: %s", first.Format("January 2006"))))
+        b.WriteString("\n")
+
+        const cellWidth = 11
+        cell := lipgloss.NewStyle().Width(cellWidth)
+        var header []string
+        for _, day := range dayNames {
+                header = append(header, cell.Render(day[:3]))
+        }
+        b.WriteString(helpStyle.Render(strings.Join(header, "")))
+        b.WriteString("\n")
+
+        today := startOfDay(time.Now())
+        var row []string
+        for i := 0; i < int(first.Weekday()); i++ {
+                row = append(row, cell.Render(""))
+        }
+        for day := first; day.Before(next); day = day.AddDate(0, 0, 1) {
+                count := counts[day.Format("2006-01-02")]
+                label := fmt.Sprintf("%2d", day.Day())
+                switch {
+                case day.Equal(selected):
+                        label = calendarSelectedStyle.Render(label)
+                case day.Equal(today):
+                        label = calendarTodayStyle.Render(label)
+                }
+                runsText := loadStyle(count, busiest).Render(fmt.Sprintf("%5d", count))
+                row = append(row, cell.Render(label+runsText))
+
+                if day.Weekday() == time.Saturday {
+                        b.WriteString(strings.Join(row, ""))
+                        b.WriteString("\n")
+                        row = nil
+                }
+        }
+        if len(row) > 0 {
+                b.WriteString(strings.Join(row, ""))
+                b.WriteString("\n")
+        }
+
+        b.WriteString("\n")
+        b.WriteString(helpStyle.Render(fmt.Sprintf("%d runs this month • %d on %s",
+                len(runs), counts[selected.Format("2006-01-02")], selected.Format("Mon Jan 2"))))
+        b.WriteString("\n")
+
+        return b.String()
+}
+
+// viewCalendarWeek renders the week containing the selected day, one line per day
+func (m Model) viewCalendarWeek() string {
+        var b strings.Builder
+        selected := m.calendarDate
+        start := selected.AddDate(0, 0, -int(selected.Weekday()))
+        end := start.AddDate(0, 0, 7)
+
+        runs := runsBetween(m.jobs, m.visible, start, end)
+        counts := countByDay(runs)
+        busiest := 0
+        for _, count := range counts {
+                if count > busiest {
+                        busiest = count
+                }
+        }
+
+        b.WriteString(titleStyle.Render(fmt.Sprintf("Week of %s", start.Format("Jan 2, 2006"))))
+        b.WriteString("\n")
+
+        for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+                key := day.Format("2006-01-02")
+                label := day.Format("Mon Jan 2")
+                if day.Equal(selected) {
+                        label = calendarSelectedStyle.Render(label)
+                }
+                b.WriteString(fmt.Sprintf("%s %s  ", label, loadStyle(counts[key], busiest).Render(fmt.Sprintf("%5d runs", counts[key]))))
+
+                // Summarise which jobs run that day and how often
+                perJob := map[int]int{}
+                var order []int
+                for _, run := range runs {
+                        if run.Time.Format("2006-01-02") != key {
+                                continue
+                        }
+                        if perJob[run.Job] == 0 {
+                                order = append(order, run.Job)
+                        }
+                        perJob[run.Job]++
+                }
+                var parts []string
+                for _, job := range order {
+                        if perJob[job] == 1 {
+                                parts = append(parts, jobName(m.jobs[job]))
+                        } else {
+                                parts = append(parts, fmt.Sprintf("%s ×%d", jobName(m.jobs[job]), perJob[job]))
+                        }
+                }
+                b.WriteString(helpStyle.Render(truncateText(strings.Join(parts, ", "), m.width-40)))
+                b.WriteString("\n")
+        }
+
+        return b.String()
+}
+
+// viewCalendarDay renders an hourly timeline of the selected day
+func (m Model) viewCalendarDay() string {
+        var b strings.Builder
+        start := m.calendarDate
+        runs := runsBetween(m.jobs, m.visible, start, start.AddDate(0, 0, 1))
+
+        b.WriteString(titleStyle.Render(fmt.Sprintf("Timeline: %s (%d runs)", start.Format("Monday, January 2, 2006"), len(runs))))
+        b.WriteString("\n")
+
+        // Group the runs by hour, then by job within the hour
+        byHour := make([][]jobRun, 24)
+        busiest := 0
+        for _, run := range runs {
+                byHour[run.Time.Hour()] = append(byHour[run.Time.Hour()], run)
+                if len(byHour[run.Time.Hour()]) > busiest {
+                        busiest = len(byHour[run.Time.Hour()])
+                }
+        }
+
+        for hour, hourRuns := range byHour {
+                bar := strings.Repeat("█", barLength(len(hourRuns), busiest, 10))
+                b.WriteString(fmt.Sprintf("%02d:00 %s ", hour, loadStyle(len(hourRuns), busiest).Render(fmt.Sprintf("%-10s", bar))))
+
+                minutesByJob := map[int][]string{}
+                var order []int
+                for _, run := range hourRuns {
+                        if minutesByJob[run.Job] == nil {
+                                order = append(order, run.Job)
+                        }
+                        minutesByJob[run.Job] = append(minutesByJob[run.Job], run.Time.Format(":04"))
+                }
+                var parts []string
+                for _, job := range order {
+                        minutes := minutesByJob[job]
+                        if len(minutes) > 4 {
+                                minutes = append(minutes[:3], fmt.Sprintf("… %d runs", len(minutesByJob[job])))
+                        }
+                        parts = append(parts, fmt.Sprintf("%s (%s)", jobName(m.jobs[job]), strings.Join(minutes, " ")))
+                }
+                b.WriteString(helpStyle.Render(truncateText(strings.Join(parts, ", "), m.width-24)))
+                b.WriteString("\n")
+        }
+
+        return b.String()
+}
+
+// barLength scales count to a bar of at most width cells, never hiding a non-zero count
+func barLength(count, busiest, width int) int {
+        if count == 0 || busiest == 0 {
+                return 0
+        }
+        n := count * width / busiest
+        if n == 0 {
+                n = 1
+        }
+        return n
+}
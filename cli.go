@@ -0,0 +1,632 @@
+package main
+
+import (
+        "errors"
+        "flag"
+        "fmt"
+        "os"
+        "sort"
+        "strconv"
+        "strings"
+        "text/tabwriter"
+        "time"
+)
+
+// cliCommand is a subcommand run from the shell instead of the TUI
+type cliCommand struct {
+        Name    string
+        Usage   string
+        Summary string
+        Run     func(args []string) int
+}
+
+// cliCommands lists the subcommands in the order `tuicron help` shows them
+var cliCommands []cliCommand
+
+func init() {
+        cliCommands = []cliCommand{
+                {"list", "list [--system] [--output table|json|yaml]", "List jobs with their number, status and next run", runList},
+                {"add", "add --expr <schedule> --command <cmd> [job flags]", "Add a job", runAdd},
+                {"edit", "edit <job> [job flags]", "Change a job, only the given flags are updated", runEdit},
+                {"rm", "rm <job>", "Remove a job", runRemove},
+                {"enable", "enable <job>", "Let cron run a disabled job again", runEnable},
+                {"disable", "disable <job>", "Keep a job in the crontab but stop cron running it", runDisable},
+                {"run", "run <job>", "Run a job now, with its logging, lock, timeout and retries", runRun},
+                {"history", "history <job> [-n <lines>] [--output table|json|yaml]", "Print the end of a job's log file", runHistory},
+                {"next", "next [job] [-n <count>]", "Print upcoming runs of one job or of all jobs", runNext},
+                {"export", "export [file | -] [--output yaml|json]", "Write every job to a spec file for version control", runExport},
+                {"apply", "apply <file | -> [--dry-run] [--yes]", "Show the changes needed to match a spec file, then make them", runApply},
+                {"import", "import <file> [--select 1,3] [--dry-run]", "Add the jobs from a crontab file or spec that aren't installed yet", runImport},
+                {"systemd", "systemd <job> [--disable-cron] [--dry-run]", "Write a systemd user timer that runs the job, optionally switching over to it", runSystemd},
+                {"lint", "lint [crontab file | -]", "Check the crontab for common mistakes", runLint},
+                {"exec", "exec --job <id> [options] -- <command>", "Run a command the way cron does, used in the crontab", runExec},
+                {"help", "help", "Show this list", runHelp},
+        }
+}
+
+// findCLICommand returns the subcommand with the given name
+func findCLICommand(name string) (cliCommand, bool) {
+        for _, command := range cliCommands {
+                if command.Name == name {
+                        return command, true
+                }
+        }
+        return cliCommand{}, false
+}
+
+// runHelp implements `tuicron help`
+func runHelp(args []string) int {
+        fmt.Println("usage: tuicron [command]")
+        fmt.Println()
+        fmt.Println("Without a command tuicron starts the interactive job manager.")
+        fmt.Println()
+        w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+        for _, command := range cliCommands {
+                fmt.Fprintf(w, "  %s\t%s\n", command.Usage, command.Summary)
+        }
+        w.Flush()
+        fmt.Println()
+        fmt.Println("<job> is a number from `tuicron list`, a log file name or a description.")
+        fmt.Println("Job flags: --expr, --command, --desc, --log, --tags, --timeout, --kill-after,")
+        fmt.Println("--retry, --notify, --no-overlap and --disabled. Run a command with -h for details.")
+        return 0
+}
+
+// jobFlags are the flags add and edit use to describe a job
+type jobFlags struct {
+        expression  string
+        command     string
+        description string
+        logFile     string
+        tags        string
+        timeout     string
+        killAfter   string
+        retry       string
+        notify      string
+        noOverlap   bool
+        disabled    bool
+}
+
+// register adds the job flags to a flag set
+func (f *jobFlags) register(flags *flag.FlagSet) {
+        flags.StringVar(&f.expression, "expr", "", "cron expression, or a phrase such as \"every weekday at 9am\"")
+        flags.StringVar(&f.command, "command", "", "command to run")
+        flags.StringVar(&f.description, "desc", "", "description")
+        flags.StringVar(&f.logFile, "log", "", "log file name under ~/.cron_history, empty for no logging")
+        flags.StringVar(&f.tags, "tags", "", "comma separated tags")
+        flags.StringVar(&f.timeout, "timeout", "", "stop runs after this long, such as 30m, empty for no limit")
+        flags.StringVar(&f.killAfter, "kill-after", "", "grace period between SIGTERM and SIGKILL")
+        flags.StringVar(&f.retry, "retry", "", "retry policy such as \"3, 30s, x2\", empty for none")
+        flags.StringVar(&f.notify, "notify", "", "command run when a run fails")
+        flags.BoolVar(&f.noOverlap, "no-overlap", false, "skip a run while the previous one is still going")
+        flags.BoolVar(&f.disabled, "disabled", false, "keep the job in the crontab without running it")
+}
+
+// apply sets the fields of job named by the flags that were given, checking
+// each value the way the edit form does
+func (f *jobFlags) apply(job *CronJob, set map[string]bool) error {
+        if set["expr"] {
+                expression := strings.TrimSpace(f.expression)
+                if err := ValidateCronExpression(expression); err != nil {
+                        schedule, naturalErr := ParseNaturalSchedule(expression)
+                        if naturalErr != nil {
+                                return fmt.Errorf("invalid cron expression: %v", err)
+                        }
+                        expression = schedule.Expression
+                }
+                job.Expression = expression
+        }
+        if set["command"] {
+                job.Command = StripPercentEscapes(strings.TrimSpace(f.command))
+        }
+        if set["desc"] {
+                job.Description = f.description
+        }
+        if set["log"] {
+                if f.logFile != "" {
+                        if err := ValidateLogFileName(f.logFile); err != nil {
+                                return fmt.Errorf("invalid log file name: %v", err)
+                        }
+                }
+                job.LogFile = f.logFile
+        }
+        if set["tags"] {
+                job.Tags = ParseTags(f.tags)
+        }
+        if set["timeout"] {
+                job.Timeout = 0
+                if value := strings.TrimSpace(f.timeout); value != "" {
+                        timeout, err := ParseTimeout(value)
+                        if err != nil {
+                                return fmt.Errorf("invalid timeout: %v", err)
+                        }
+                        job.Timeout = timeout
+                }
+        }
+        if set["kill-after"] {
+                job.KillAfter = 0
+                if value := strings.TrimSpace(f.killAfter); value != "" {
+                        killAfter, err := ParseTimeout(value)
+                        if err != nil {
+                                return fmt.Errorf("invalid kill after: %v", err)
+                        }
+                        job.KillAfter = killAfter
+                }
+        }
+        if job.Timeout > 0 && job.KillAfter == 0 {
+                job.KillAfter = defaultKillAfter
+        }
+        if job.Timeout == 0 {
+                job.KillAfter = 0
+        }
+        if set["retry"] {
+                job.Retry = RetryPolicy{}
+                if value := strings.TrimSpace(f.retry); value != "" {
+                        retry, err := ParseRetryPolicy(value)
+                        if err != nil {
+                                return fmt.Errorf("invalid retry policy: %v", err)
+                        }
+                        job.Retry = retry
+                }
+        }
+        if set["notify"] {
+                job.Notify = StripPercentEscapes(strings.TrimSpace(f.notify))
+        }
+        if set["no-overlap"] {
+                job.NoOverlap = f.noOverlap
+        }
+        if set["disabled"] {
+                job.Disabled = f.disabled
+        }
+
+        if job.Expression == "" {
+                return fmt.Errorf("--expr is required")
+        }
+        if job.Command == "" {
+                return fmt.Errorf("--command is required")
+        }
+        return nil
+}
+
+// newCLIFlags returns a flag set for a subcommand that prints its usage on -h
+func newCLIFlags(name string) *flag.FlagSet {
+        flags := flag.NewFlagSet(name, flag.ContinueOnError)
+        flags.Usage = func() {
+                command, _ := findCLICommand(name)
+                fmt.Fprintf(flags.Output(), "usage: tuicron %s\n", command.Usage)
+                flags.PrintDefaults()
+        }
+        return flags
+}
+
+// parseJobArgs parses a subcommand's flags and returns the <job> argument,
+// which may come before or after the flags. required says whether it must be given.
+func parseJobArgs(flags *flag.FlagSet, args []string, required bool) (string, error) {
+        var ref string
+        if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+                ref, args = args[0], args[1:]
+        }
+        if err := flags.Parse(args); err != nil {
+                return "", err
+        }
+        if ref == "" && flags.NArg() > 0 {
+                ref = flags.Arg(0)
+        } else if flags.NArg() > 0 {
+                return "", fmt.Errorf("unexpected argument %q", flags.Arg(0))
+        }
+        if ref == "" && required {
+                return "", fmt.Errorf("which job? Give its number from tuicron list, its log file name or its description")
+        }
+        return ref, nil
+}
+
+// cliFail reports a subcommand error, returning 2 for usage mistakes and 1 otherwise
+func cliFail(name string, flags *flag.FlagSet, err error) int {
+        if errors.Is(err, flag.ErrHelp) {
+                return 0
+        }
+        fmt.Fprintf(os.Stderr, "tuicron %s: %v\n", name, err)
+        if flags != nil {
+                flags.Usage()
+                return 2
+        }
+        return 1
+}
+
+// loadCLIJobs reads the installed crontab. Unlike the TUI it never falls back
+// to sample jobs, since those would be written back.
+func loadCLIJobs() ([]CronJob, error) {
+        content, err := ReadCrontabText()
+        if err != nil {
+                return nil, err
+        }
+        return ParseCrontab(content)
+}
+
+// findJob resolves a <job> argument: a number from `tuicron list`, or a log
+// file name, job id or description
+func findJob(jobs []CronJob, ref string) (int, error) {
+        if n, err := strconv.Atoi(ref); err == nil {
+                if n < 1 || n > len(jobs) {
+                        return -1, fmt.Errorf("there is no job %d, the crontab has %s", n, countNoun(len(jobs), "job"))
+                }
+                return n - 1, nil
+        }
+
+        var found []int
+        for i, job := range jobs {
+                if job.LogFile == ref || JobID(job) == ref || strings.EqualFold(job.Description, ref) {
+                        found = append(found, i)
+                }
+        }
+        switch len(found) {
+        case 0:
+                return -1, fmt.Errorf("no job matches %q", ref)
+        case 1:
+                return found[0], nil
+        default:
+                return -1, fmt.Errorf("%q matches %s, use the number from tuicron list", ref, countNoun(len(found), "job"))
+        }
+}
+
+// warnSecrets tells the user about credentials in a job's command
+func warnSecrets(job CronJob) {
+        for _, secret := range FindSecrets(job.Command) {
+                fmt.Fprintf(os.Stderr, "warning: the command contains what looks like %s, consider moving it to an env file\n", secret.Kind)
+        }
+}
+
+// runList implements `tuicron list`
+func runList(args []string) int {
+        flags := newCLIFlags("list")
+        output := flags.String("output", OutputTable, "output format: table, json or yaml")
+        flags.StringVar(output, "o", OutputTable, "shorthand for --output")
+        system := flags.Bool("system", false, "also list the read-only jobs in /etc/crontab, /etc/cron.d and the run-parts directories")
+        if err := flags.Parse(args); err != nil {
+                return cliFail("list", flags, err)
+        }
+        if err := checkOutputFormat(*output); err != nil {
+                return cliFail("list", flags, err)
+        }
+        jobs, err := loadCLIJobs()
+        if err != nil {
+                return cliFail("list", nil, err)
+        }
+
+        // System jobs come after the user's and have no number, since no
+        // other subcommand can change them
+        if *system {
+                systemJobs, err := ReadSystemJobs()
+                if err != nil {
+                        fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+                }
+                jobs = append(jobs, systemJobs...)
+        }
+        number := func(i int) int {
+                if jobs[i].Source != SourceCrontab {
+                        return 0
+                }
+                return i + 1
+        }
+
+        if *output != OutputTable {
+                now := time.Now()
+                records := make([]jobRecord, len(jobs))
+                for i, job := range jobs {
+                        records[i] = newJobRecord(job, number(i), now)
+                }
+                if err := writeStructured(os.Stdout, *output, records); err != nil {
+                        return cliFail("list", nil, err)
+                }
+                return 0
+        }
+
+        w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+        if *system {
+                fmt.Fprintln(w, "#\tSTATUS\tSCHEDULE\tNEXT RUN\tSOURCE\tDESCRIPTION\tCOMMAND")
+        } else {
+                fmt.Fprintln(w, "#\tSTATUS\tSCHEDULE\tNEXT RUN\tDESCRIPTION\tCOMMAND")
+        }
+        for i, job := range jobs {
+                n := "-"
+                if number(i) > 0 {
+                        n = strconv.Itoa(number(i))
+                }
+                nextRun := "-"
+                if !job.NextRun.IsZero() {
+                        nextRun = job.NextRun.Format("2006-01-02 15:04")
+                }
+                description := job.Description
+                if description == "" {
+                        description = "-"
+                }
+                if *system {
+                        source := "crontab"
+                        if job.Source == SourceSystem {
+                                source = fmt.Sprintf("%s (%s)", job.SourceFile, job.User)
+                        }
+                        fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", n, job.LastStatus, job.Expression, nextRun, source, description, job.Command)
+                        continue
+                }
+                fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", n, job.LastStatus, job.Expression, nextRun, description, job.Command)
+        }
+        w.Flush()
+        return 0
+}
+
+// runAdd implements `tuicron add`
+func runAdd(args []string) int {
+        var f jobFlags
+        flags := newCLIFlags("add")
+        f.register(flags)
+        if err := flags.Parse(args); err != nil {
+                return cliFail("add", flags, err)
+        }
+        if flags.NArg() > 0 {
+                return cliFail("add", flags, fmt.Errorf("unexpected argument %q", flags.Arg(0)))
+        }
+
+        set := map[string]bool{}
+        flags.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+        var job CronJob
+        if err := f.apply(&job, set); err != nil {
+                return cliFail("add", flags, err)
+        }
+
+        jobs, err := loadCLIJobs()
+        if err != nil {
+                return cliFail("add", nil, err)
+        }
+        if err := saveCLIJob(&job); err != nil {
+                return cliFail("add", nil, err)
+        }
+        jobs = append(jobs, job)
+        if err := WriteCrontab(jobs); err != nil {
+                return cliFail("add", nil, err)
+        }
+
+        warnSecrets(job)
+        fmt.Printf("Added job %d: %s\n", len(jobs), jobName(job))
+        return 0
+}
+
+// runEdit implements `tuicron edit`
+func runEdit(args []string) int {
+        var f jobFlags
+        flags := newCLIFlags("edit")
+        f.register(flags)
+        ref, err := parseJobArgs(flags, args, true)
+        if err != nil {
+                return cliFail("edit", flags, err)
+        }
+
+        jobs, err := loadCLIJobs()
+        if err != nil {
+                return cliFail("edit", nil, err)
+        }
+        index, err := findJob(jobs, ref)
+        if err != nil {
+                return cliFail("edit", nil, err)
+        }
+
+        set := map[string]bool{}
+        flags.Visit(func(fl *flag.Flag) { set[fl.Name] = true })
+        if len(set) == 0 {
+                return cliFail("edit", flags, fmt.Errorf("nothing to change"))
+        }
+        job := jobs[index]
+        if err := f.apply(&job, set); err != nil {
+                return cliFail("edit", flags, err)
+        }
+        if err := saveCLIJob(&job); err != nil {
+                return cliFail("edit", nil, err)
+        }
+        jobs[index] = job
+        if err := WriteCrontab(jobs); err != nil {
+                return cliFail("edit", nil, err)
+        }
+
+        warnSecrets(job)
+        fmt.Printf("Updated job %d: %s\n", index+1, jobName(job))
+        return 0
+}
+
+// saveCLIJob fills in the fields worked out from a job's settings and creates
+// its log file, as saving from the edit form does
+func saveCLIJob(job *CronJob) error {
+        if job.LogFile != "" {
+                if err := CreateLogFile(job.LogFile); err != nil {
+                        return fmt.Errorf("could not create log file: %v", err)
+                }
+        }
+        job.LastRun = GetLastRunFromLogFile(job.LogFile)
+        SetJobDisabled(job, job.Disabled)
+        return nil
+}
+
+// runJobCommand runs a subcommand that takes a <job> and changes the crontab
+func runJobCommand(name string, args []string, change func(jobs []CronJob, index int) ([]CronJob, string)) int {
+        flags := newCLIFlags(name)
+        ref, err := parseJobArgs(flags, args, true)
+        if err != nil {
+                return cliFail(name, flags, err)
+        }
+
+        jobs, err := loadCLIJobs()
+        if err != nil {
+                return cliFail(name, nil, err)
+        }
+        index, err := findJob(jobs, ref)
+        if err != nil {
+                return cliFail(name, nil, err)
+        }
+
+        jobs, message := change(jobs, index)
+        if err := WriteCrontab(jobs); err != nil {
+                return cliFail(name, nil, err)
+        }
+        fmt.Println(message)
+        return 0
+}
+
+// runRemove implements `tuicron rm`
+func runRemove(args []string) int {
+        return runJobCommand("rm", args, func(jobs []CronJob, index int) ([]CronJob, string) {
+                message := fmt.Sprintf("Removed job %d: %s", index+1, jobName(jobs[index]))
+                return append(jobs[:index], jobs[index+1:]...), message
+        })
+}
+
+// runEnable implements `tuicron enable`
+func runEnable(args []string) int {
+        return runJobCommand("enable", args, func(jobs []CronJob, index int) ([]CronJob, string) {
+                SetJobDisabled(&jobs[index], false)
+                return jobs, fmt.Sprintf("Enabled job %d: %s", index+1, jobName(jobs[index]))
+        })
+}
+
+// runDisable implements `tuicron disable`
+func runDisable(args []string) int {
+        return runJobCommand("disable", args, func(jobs []CronJob, index int) ([]CronJob, string) {
+                SetJobDisabled(&jobs[index], true)
+                return jobs, fmt.Sprintf("Disabled job %d: %s", index+1, jobName(jobs[index]))
+        })
+}
+
+// runRun implements `tuicron run`, running a job now through the same runner
+// cron uses, with the same input, and exiting with its exit code
+func runRun(args []string) int {
+        flags := newCLIFlags("run")
+        ref, err := parseJobArgs(flags, args, true)
+        if err != nil {
+                return cliFail("run", flags, err)
+        }
+
+        jobs, err := loadCLIJobs()
+        if err != nil {
+                return cliFail("run", nil, err)
+        }
+        index, err := findJob(jobs, ref)
+        if err != nil {
+                return cliFail("run", nil, err)
+        }
+
+        job := jobs[index]
+        if job.LogFile != "" {
+                fmt.Fprintf(os.Stderr, "Running %s, output goes to %s\n", jobName(job), GetLogFilePath(job.LogFile))
+        }
+        return execWithInput(execArgs(job)[1:], strings.NewReader(CronStdin(job.Stdin)))
+}
+
+// runHistory implements `tuicron history`
+func runHistory(args []string) int {
+        flags := newCLIFlags("history")
+        lines := flags.Int("n", 20, "number of log lines to print, 0 for all")
+        output := flags.String("output", OutputTable, "output format: table, json or yaml")
+        flags.StringVar(output, "o", OutputTable, "shorthand for --output")
+        ref, err := parseJobArgs(flags, args, true)
+        if err != nil {
+                return cliFail("history", flags, err)
+        }
+        if err := checkOutputFormat(*output); err != nil {
+                return cliFail("history", flags, err)
+        }
+
+        jobs, err := loadCLIJobs()
+        if err != nil {
+                return cliFail("history", nil, err)
+        }
+        index, err := findJob(jobs, ref)
+        if err != nil {
+                return cliFail("history", nil, err)
+        }
+
+        job := jobs[index]
+        if job.LogFile == "" {
+                return cliFail("history", nil, fmt.Errorf("%s has no log file", jobName(job)))
+        }
+
+        // The log comes back newest first, print the most recent lines oldest first
+        entries := GetJobHistoryFromLogFile(job.LogFile)
+        if *lines > 0 && len(entries) > *lines {
+                entries = entries[:*lines]
+        }
+        if *output != OutputTable {
+                records := make([]historyRecord, 0, len(entries))
+                for i := len(entries) - 1; i >= 0; i-- {
+                        records = append(records, newHistoryRecord(entries[i]))
+                }
+                if err := writeStructured(os.Stdout, *output, records); err != nil {
+                        return cliFail("history", nil, err)
+                }
+                return 0
+        }
+        for i := len(entries) - 1; i >= 0; i-- {
+                fmt.Println(entries[i].Message)
+        }
+        return 0
+}
+
+// runNext implements `tuicron next`
+func runNext(args []string) int {
+        flags := newCLIFlags("next")
+        count := flags.Int("n", 5, "number of runs to print")
+        ref, err := parseJobArgs(flags, args, false)
+        if err != nil {
+                return cliFail("next", flags, err)
+        }
+        if *count < 1 {
+                return cliFail("next", flags, fmt.Errorf("-n must be at least 1"))
+        }
+
+        jobs, err := loadCLIJobs()
+        if err != nil {
+                return cliFail("next", nil, err)
+        }
+
+        indices := make([]int, 0, len(jobs))
+        if ref != "" {
+                index, err := findJob(jobs, ref)
+                if err != nil {
+                        return cliFail("next", nil, err)
+                }
+                if jobs[index].Disabled {
+                        return cliFail("next", nil, fmt.Errorf("%s is disabled", jobName(jobs[index])))
+                }
+                indices = append(indices, index)
+        } else {
+                for i, job := range jobs {
+                        if !job.Disabled {
+                                indices = append(indices, i)
+                        }
+                }
+        }
+
+        // Take the first runs of every job, then keep the earliest overall
+        var runs []jobRun
+        now := time.Now()
+        for _, i := range indices {
+                times, err := GetNextRunTimes(jobs[i].Expression, now, *count)
+                if err != nil {
+                        continue
+                }
+                for _, t := range times {
+                        runs = append(runs, jobRun{Job: i, Time: t})
+                }
+        }
+        sort.SliceStable(runs, func(a, b int) bool { return runs[a].Time.Before(runs[b].Time) })
+        if len(runs) > *count {
+                runs = runs[:*count]
+        }
+
+        w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+        for _, run := range runs {
+                if ref != "" {
+                        fmt.Fprintf(w, "%s\n", run.Time.Format("Mon 2006-01-02 15:04"))
+                } else {
+                        fmt.Fprintf(w, "%s\t%d\t%s\n", run.Time.Format("Mon 2006-01-02 15:04"), run.Job+1, jobName(jobs[run.Job]))
+                }
+        }
+        w.Flush()
+        return 0
+}
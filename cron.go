@@ -5,8 +5,9 @@ import (
         "fmt"
         "os"
         "os/exec"
+        "path/filepath"
         "regexp"
-        "strconv"
+        "sort"
         "strings"
         "time"
 
@@ -16,122 +17,203 @@ import (
 // CronJob represents a single cron job entry
 type CronJob struct {
         Description string
-        Expression  string
+        Expression  string        // Cron expression, or the OnCalendar= schedule of a timer
         Command     string
-        LogFile     string    // Log file name without extension
+        LogFile     string        // Log file name without extension
         NextRun     time.Time
         LastRun     time.Time
+        LastStatus  string        // Outcome of the most recent run, see Status* constants
+        Tags        []string
+        Env         []string      // NAME=value assignments in effect for the job
+        NoOverlap   bool          // Skip a run while the previous one is still going
+        Timeout     time.Duration // Stop runs that take longer than this, 0 for no limit
+        KillAfter   time.Duration // Grace period between SIGTERM and SIGKILL
+        Retry       RetryPolicy   // Run again after a failure
+        Notify      string        // Command run when a run fails, needs the tuicron runner
+        Stdin       string        // Text cron sends to the command, from after a bare %
+        Disabled    bool          // Kept in the crontab as a comment so cron skips it
+        Source      string        // Where the job is defined, see Source* constants
+        TimerUnit   string        // systemd timer, for jobs from SourceTimer
+        ServiceUnit string        // Unit the timer starts
+        SourceFile  string        // File or directory a system job was read from
+        User        string        // Account a system job runs as
 }
 
-// ParseCronExpression converts a cron expression to human-readable text
-func ParseCronExpression(expr string) string {
-        parts := strings.Fields(expr)
-        if len(parts) < 5 {
-                return "Invalid cron expression"
-        }
+// Job status values derived from a job's log file
+const (
+        StatusNoLog    = "-"
+        StatusNeverRun = "Never run"
+        StatusOK       = "OK"
+        StatusError    = "Error"
+        StatusDisabled = "Disabled"
+)
 
-        minute := parts[0]
-        hour := parts[1]
-        day := parts[2]
-        month := parts[3]
-        weekday := parts[4]
+// Job sources. Only jobs from the user's crontab are written back.
+const (
+        SourceCrontab = ""
+        SourceTimer   = "timer"
+        SourceSystem  = "system"
+)
 
-        var description []string
+// jobType returns the label the table's Type column shows for a job
+func jobType(job CronJob) string {
+        if job.Source == SourceCrontab {
+                return "cron"
+        }
+        return job.Source
+}
 
-        // Parse minute
-        if minute == "*" {
-                description = append(description, "every minute")
-        } else if strings.Contains(minute, "/") {
-                interval := strings.Split(minute, "/")[1]
-                description = append(description, fmt.Sprintf("every %s minutes", interval))
-        } else if strings.Contains(minute, ",") {
-                description = append(description, fmt.Sprintf("at minutes %s", minute))
-        } else {
-                description = append(description, fmt.Sprintf("at minute %s", minute))
+// readOnlyReason explains why a job can't be edited, removed or converted in
+// tuicron, or returns "" when it can
+func readOnlyReason(job CronJob) string {
+        switch job.Source {
+        case SourceTimer:
+                return fmt.Sprintf("%s is a systemd timer, change it with systemctl --user edit --full %s", job.TimerUnit, job.ServiceUnit)
+        case SourceSystem:
+                return fmt.Sprintf("System jobs are read-only, this one is in %s", job.SourceFile)
         }
+        return ""
+}
 
-        // Parse hour
-        if hour != "*" {
-                if strings.Contains(hour, "/") {
-                        interval := strings.Split(hour, "/")[1]
-                        description = append(description, fmt.Sprintf("every %s hours", interval))
-                } else if strings.Contains(hour, ",") {
-                        description = append(description, fmt.Sprintf("at hours %s", hour))
-                } else {
-                        h, _ := strconv.Atoi(hour)
-                        if h == 0 {
-                                description = append(description, "at midnight")
-                        } else if h == 12 {
-                                description = append(description, "at noon")
-                        } else if h < 12 {
-                                description = append(description, fmt.Sprintf("at %d AM", h))
-                        } else {
-                                description = append(description, fmt.Sprintf("at %d PM", h-12))
-                        }
+// ParseTags splits a comma separated list of tags, dropping empty entries
+func ParseTags(value string) []string {
+        var tags []string
+        for _, tag := range strings.Split(value, ",") {
+                if tag = strings.TrimSpace(tag); tag != "" {
+                        tags = append(tags, tag)
                 }
         }
+        return tags
+}
 
-        // Parse day of month
-        if day != "*" {
-                if strings.Contains(day, "/") {
-                        interval := strings.Split(day, "/")[1]
-                        description = append(description, fmt.Sprintf("every %s days", interval))
-                } else {
-                        description = append(description, fmt.Sprintf("on day %s", day))
-                }
+// parseSchedule parses a five-field cron expression. cron accepts 7 for Sunday
+// as well as 0, which the schedule parser doesn't, so it is rewritten first.
+func parseSchedule(expr string) (cron.Schedule, error) {
+        parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+        return parser.Parse(normalizeSunday(expr))
+}
+
+// normalizeSunday rewrites the day of week items that use 7 for Sunday, such
+// as "7", "5-7" or "1-7/2", to use 0 instead
+func normalizeSunday(expr string) string {
+        fields := strings.Fields(expr)
+        if len(fields) != 5 {
+                return expr
         }
 
-        // Parse month
-        if month != "*" {
-                months := map[string]string{
-                        "1": "January", "2": "February", "3": "March", "4": "April",
-                        "5": "May", "6": "June", "7": "July", "8": "August",
-                        "9": "September", "10": "October", "11": "November", "12": "December",
+        items := strings.Split(fields[4], ",")
+        for i, item := range items {
+                r, err := parseCronRange(item, dowBounds)
+                if err != nil || r.Every || r.Open || r.End != 7 {
+                        continue
                 }
-                if monthName, ok := months[month]; ok {
-                        description = append(description, fmt.Sprintf("in %s", monthName))
-                } else {
-                        description = append(description, fmt.Sprintf("in month %s", month))
+                step := r.Step
+                if step == 0 {
+                        step = 1
                 }
-        }
-
-        // Parse weekday
-        if weekday != "*" {
-                weekdays := map[string]string{
-                        "0": "Sunday", "1": "Monday", "2": "Tuesday", "3": "Wednesday",
-                        "4": "Thursday", "5": "Friday", "6": "Saturday", "7": "Sunday",
+                days := map[int]bool{}
+                for v := r.Start; v <= r.End; v += step {
+                        days[v%7] = true
                 }
-                if weekdayName, ok := weekdays[weekday]; ok {
-                        description = append(description, fmt.Sprintf("on %s", weekdayName))
-                } else {
-                        description = append(description, fmt.Sprintf("on weekday %s", weekday))
+                var values []int
+                for v := range days {
+                        values = append(values, v)
                 }
+                sort.Ints(values)
+                items[i] = compressValues(values)
         }
-
-        if len(description) == 0 {
-                return "Every minute"
-        }
-
-        return strings.Join(description, ", ")
+        fields[4] = strings.Join(items, ",")
+        return strings.Join(fields, " ")
 }
 
 // ValidateCronExpression checks if a cron expression is valid
 func ValidateCronExpression(expr string) error {
-        parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-        _, err := parser.Parse(expr)
+        _, err := parseSchedule(expr)
         return err
 }
 
 // GetNextRunTime calculates the next execution time for a cron expression
 func GetNextRunTime(expr string) (time.Time, error) {
-        parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-        schedule, err := parser.Parse(expr)
+        schedule, err := parseSchedule(expr)
         if err != nil {
                 return time.Time{}, err
         }
         return schedule.Next(time.Now()), nil
 }
 
+// GetNextRunTimes calculates the next n execution times after from
+func GetNextRunTimes(expr string, from time.Time, n int) ([]time.Time, error) {
+        schedule, err := parseSchedule(expr)
+        if err != nil {
+                return nil, err
+        }
+
+        var times []time.Time
+        next := from
+        for i := 0; i < n; i++ {
+                next = schedule.Next(next)
+                if next.IsZero() {
+                        break
+                }
+                times = append(times, next)
+        }
+        return times, nil
+}
+
+// GetRunTimesBetween returns every execution time in [start, end), giving up
+// after limit runs
+func GetRunTimesBetween(expr string, start, end time.Time, limit int) ([]time.Time, error) {
+        schedule, err := parseSchedule(expr)
+        if err != nil {
+                return nil, err
+        }
+
+        var times []time.Time
+        next := schedule.Next(start.Add(-time.Second))
+        for !next.IsZero() && next.Before(end) && len(times) < limit {
+                times = append(times, next)
+                next = schedule.Next(next)
+        }
+        return times, nil
+}
+
+// ScheduleWarnings points out expressions that never or rarely fire, and the
+// easily misread case where day of month and day of week are both restricted
+func ScheduleWarnings(expr string) []string {
+        schedule, err := parseSchedule(expr)
+        if err != nil {
+                return nil
+        }
+
+        var warnings []string
+        now := time.Now()
+        yearFromNow := now.AddDate(1, 0, 0)
+
+        // Count runs over the next year, stopping early once it's clearly frequent
+        count := 0
+        next := schedule.Next(now)
+        if next.IsZero() {
+                warnings = append(warnings, "This expression never fires: no date matches all fields")
+        } else if next.After(yearFromNow) {
+                warnings = append(warnings, fmt.Sprintf("Next run is more than a year away (%s)", next.Format("Jan 2, 2006 15:04")))
+        } else {
+                for !next.IsZero() && !next.After(yearFromNow) && count < 12 {
+                        count++
+                        next = schedule.Next(next)
+                }
+                if count < 12 {
+                        warnings = append(warnings, fmt.Sprintf("Rarely fires: only %d run(s) in the next year", count))
+                }
+        }
+
+        fields := strings.Fields(expr)
+        if len(fields) == 5 && fields[2] != "*" && fields[2] != "?" && fields[4] != "*" && fields[4] != "?" {
+                warnings = append(warnings, "Day of month and day of week are both set: cron runs when EITHER matches, not both")
+        }
+
+        return warnings
+}
+
 // ReadCrontab reads the current user's crontab
 func ReadCrontab() ([]CronJob, error) {
         cmd := exec.Command("crontab", "-l")
@@ -163,6 +245,19 @@ func ReadCrontab() ([]CronJob, error) {
         return jobs, nil
 }
 
+// ReadCrontabText returns the user's crontab as it is installed, or "" when
+// they don't have one. Unlike ReadCrontab it never substitutes sample jobs.
+func ReadCrontabText() (string, error) {
+        output, err := exec.Command("crontab", "-l").Output()
+        if err != nil {
+                if exitErr, ok := err.(*exec.ExitError); ok && strings.Contains(string(exitErr.Stderr), "no crontab") {
+                        return "", nil
+                }
+                return "", fmt.Errorf("failed to read crontab: %v", err)
+        }
+        return string(output), nil
+}
+
 // getSampleJobs returns some sample cron jobs for demonstration
 func getSampleJobs() []CronJob {
         jobs := []CronJob{
@@ -191,11 +286,12 @@ func getSampleJobs() []CronJob {
                 if nextRun, err := GetNextRunTime(jobs[i].Expression); err == nil {
                         jobs[i].NextRun = nextRun
                 }
-                // Set last run time from log file and create sample log files
-                jobs[i].LastRun = GetLastRunFromLogFile(jobs[i].LogFile)
-                
                 // Create sample log files with some example content
                 CreateSampleLogFile(jobs[i].LogFile)
+
+                // Set last run time and status from the log file
+                jobs[i].LastRun = GetLastRunFromLogFile(jobs[i].LogFile)
+                jobs[i].LastStatus = GetLastStatusFromLogFile(jobs[i].LogFile)
         }
         
         return jobs
@@ -233,8 +329,8 @@ func CreateSampleLogFile(logFile string) {
                 entries = []string{
                         fmt.Sprintf("%s - Starting job", now.Add(-25*time.Hour).Format("2006-01-02 15:04:05")),
                         fmt.Sprintf("%s - Backup started", now.Add(-25*time.Hour).Format("2006-01-02 15:04:05")),
-                        fmt.Sprintf("%s - Copying files...", now.Add(-25*time.Hour).Format("2006-01-02 15:04:05")),
-                        fmt.Sprintf("%s - Backup completed successfully", now.Add(-25*time.Hour).Format("2006-01-02 15:04:05")),
+                        fmt.Sprintf("%s - Copying files...", now.Add(-25*time.Hour+2*time.Minute).Format("2006-01-02 15:04:05")),
+                        fmt.Sprintf("%s - Backup completed successfully", now.Add(-25*time.Hour+72*time.Minute).Format("2006-01-02 15:04:05")),
                 }
         case "system_update":
                 entries = []string{
@@ -296,6 +392,93 @@ func GetLastRunFromLogFile(logFile string) time.Time {
         return lastTimestamp
 }
 
+// GetLastStatusFromLogFile works out how the most recent run of a job went.
+// Output following the last "Starting job" line is checked for error messages.
+func GetLastStatusFromLogFile(logFile string) string {
+        if logFile == "" {
+                return StatusNoLog
+        }
+
+        file, err := os.Open(GetLogFilePath(logFile))
+        if err != nil {
+                return StatusNeverRun
+        }
+        defer file.Close()
+
+        status := StatusNeverRun
+        scanner := bufio.NewScanner(file)
+        for scanner.Scan() {
+                line := strings.ToLower(scanner.Text())
+                if strings.Contains(line, "starting job") {
+                        status = StatusOK
+                        continue
+                }
+                // A retry starts over, so earlier failures no longer count
+                if strings.Contains(line, "retrying in") {
+                        status = StatusOK
+                        continue
+                }
+                if strings.Contains(line, "job timed out") {
+                        status = StatusTimedOut
+                        continue
+                }
+                if status == StatusOK && (strings.Contains(line, "error") || strings.Contains(line, "fail")) {
+                        status = StatusError
+                }
+        }
+
+        return status
+}
+
+// GetRunDurationsFromLogFile estimates how long each run of a job took, oldest
+// first. A run lasts from its "Starting job" line to the last timestamp logged
+// before the next one; runs that log no later timestamp are skipped.
+func GetRunDurationsFromLogFile(logFile string) []time.Duration {
+        if logFile == "" {
+                return nil
+        }
+
+        file, err := os.Open(GetLogFilePath(logFile))
+        if err != nil {
+                return nil
+        }
+        defer file.Close()
+
+        timestampRegex := regexp.MustCompile(`(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2})`)
+
+        var durations []time.Duration
+        var started, last time.Time
+        finish := func() {
+                if !started.IsZero() && last.After(started) {
+                        durations = append(durations, last.Sub(started))
+                }
+        }
+
+        scanner := bufio.NewScanner(file)
+        for scanner.Scan() {
+                line := scanner.Text()
+                matches := timestampRegex.FindStringSubmatch(line)
+                if matches == nil {
+                        continue
+                }
+                t, err := time.ParseInLocation("2006-01-02 15:04:05", matches[1], time.Local)
+                if err != nil {
+                        continue
+                }
+                if strings.Contains(strings.ToLower(line), "starting job") {
+                        finish()
+                        started, last = t, t
+                        continue
+                }
+                if !started.IsZero() && t.After(last) {
+                        last = t
+                }
+        }
+        finish()
+
+        return durations
+}
+
 // CreateLogDir creates the ~/.cron_history directory if it doesn't exist
 func CreateLogDir() error {
         homeDir, err := os.UserHomeDir()
@@ -307,11 +490,30 @@ func CreateLogDir() error {
         return os.MkdirAll(logDir, 0755)
 }
 
+// logNameRegex matches the log file names tuicron accepts: letters, digits,
+// dots, dashes and underscores, not starting with a dot or dash
+var logNameRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateLogFileName checks that a log file name is safe to use as a file
+// name and inside a crontab line
+func ValidateLogFileName(logFile string) error {
+        if !logNameRegex.MatchString(logFile) {
+                return fmt.Errorf("%q may only contain letters, digits, '.', '-' and '_', and must start with a letter or digit", logFile)
+        }
+        if strings.Contains(logFile, "..") {
+                return fmt.Errorf("%q may not contain '..'", logFile)
+        }
+        return nil
+}
+
 // CreateLogFile creates an initial log file if it doesn't exist
 func CreateLogFile(logFile string) error {
         if logFile == "" {
                 return nil
         }
+        if err := ValidateLogFileName(logFile); err != nil {
+                return err
+        }
         
         // Ensure log directory exists
         if err := CreateLogDir(); err != nil {
@@ -344,22 +546,58 @@ func GetLogFilePath(logFile string) string {
         return fmt.Sprintf("%s/.cron_history/%s.log", homeDir, logFile)
 }
 
-// AddLoggingToCommand modifies a command to include logging output
-func AddLoggingToCommand(command, logFile string) string {
-        if logFile == "" {
-                return command
+// commandWrappers describes the settings wrapped around a job's command
+type commandWrappers struct {
+        Command   string // The bare command
+        LogFile   string
+        LockFile  string
+        Retry     RetryPolicy
+        Timeout   time.Duration
+        KillAfter time.Duration
+        Notify    string
+}
+
+// parseWrappers reads the settings back out of an installed command. Commands
+// run through `tuicron exec` carry them as flags; lines installed by older
+// versions only have the logging wrapper peeled off.
+func parseWrappers(command string) commandWrappers {
+        command = strings.TrimSpace(command)
+        if opts, ok := parseRunnerCommand(command); ok {
+                wrappers := commandWrappers{
+                        Command:   opts.Command,
+                        LogFile:   opts.LogFile,
+                        Retry:     opts.Retry,
+                        Timeout:   opts.Timeout,
+                        KillAfter: opts.KillAfter,
+                        Notify:    opts.Notify,
+                }
+                if opts.Lock {
+                        wrappers.LockFile = filepath.Join(GetLockDir(), opts.Job+".lock")
+                }
+                return wrappers
         }
-        
-        homeDir, _ := os.UserHomeDir()
-        logPath := fmt.Sprintf("%s/.cron_history/%s.log", homeDir, logFile)
-        
-        // Add timestamp and redirect output, preserving the original command  
-        // Use printf with escaped % signs for cron compatibility
-        return fmt.Sprintf("{ printf '\\%%s - Starting job\\n' \"$(date '+\\%%Y-\\%%m-\\%%d \\%%H:\\%%M:\\%%S')\" && %s; } >> %s 2>&1", command, logPath)
+
+        var wrappers commandWrappers
+        wrappers.LogFile = extractLogFile(command)
+
+        // Only a command that appends to a tuicron log file has the logging
+        // wrapper, other redirects belong to the command
+        if wrappers.LogFile != "" {
+                command = stripLogging(command)
+        }
+
+        wrappers.Command = command
+        return wrappers
 }
 
-// StripLoggingFromCommand removes logging redirection from a command for display
+// StripLoggingFromCommand removes the runner or logging wrapper from a command
+// for display
 func StripLoggingFromCommand(command string) string {
+        return parseWrappers(command).Command
+}
+
+// stripLogging removes logging redirection from a command
+func stripLogging(command string) string {
         // Handle new format: { printf ... && command; } >> logfile 2>&1
         if strings.HasPrefix(command, "{ printf") && strings.Contains(command, "Starting job") {
                 // Extract the command after "&&" and before ";"
@@ -409,8 +647,13 @@ func StripLoggingFromCommand(command string) string {
 
 // ExtractLogFileFromCommand extracts the log file name from a command with logging
 func ExtractLogFileFromCommand(command string) string {
+        return parseWrappers(command).LogFile
+}
+
+// extractLogFile finds the log file a shell wrapped command appends to
+func extractLogFile(command string) string {
         // Look for ~/.cron_history/filename.log pattern
-        logRegex := regexp.MustCompile(`\.cron_history/(\w+)\.log`)
+        logRegex := regexp.MustCompile(`\.cron_history/([A-Za-z0-9][A-Za-z0-9._-]*)\.log`)
         if matches := logRegex.FindStringSubmatch(command); matches != nil {
                 return matches[1]
         }
@@ -441,11 +684,7 @@ func GetJobHistoryFromLogFile(logFile string) []LogEntry {
         for scanner.Scan() {
                 line := scanner.Text()
                 if line != "" {
-                        entry := LogEntry{
-                                Message: line,
-                                Status:  "log",
-                        }
-                        entries = append(entries, entry)
+                        entries = append(entries, parseLogEntry(line))
                 }
         }
         
@@ -457,13 +696,61 @@ func GetJobHistoryFromLogFile(logFile string) []LogEntry {
         return entries
 }
 
+// logLineRegex matches the lines tuicron and its wrappers write to a log file
+var logLineRegex = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) - (.*)$`)
+
+// parseLogEntry reads one line of a job's log file. Lines tuicron wrote get
+// their timestamp and a status from the message, anything else is the job's
+// own output.
+func parseLogEntry(line string) LogEntry {
+        entry := LogEntry{Message: line, Status: "output"}
+        matches := logLineRegex.FindStringSubmatch(line)
+        if matches == nil {
+                return entry
+        }
+        t, err := time.ParseInLocation("2006-01-02 15:04:05", matches[1], time.Local)
+        if err != nil {
+                return entry
+        }
+        entry.Timestamp = t
+
+        message := strings.ToLower(matches[2])
+        switch {
+        case strings.Contains(message, "starting job"):
+                entry.Status = "started"
+        case strings.Contains(message, "job finished"):
+                entry.Status = "finished"
+        case strings.Contains(message, "job timed out"):
+                entry.Status = "timed_out"
+        case strings.Contains(message, "retrying in"):
+                entry.Status = "retrying"
+        case strings.Contains(message, "giving up"):
+                entry.Status = "gave_up"
+        case strings.Contains(message, "skipped"):
+                entry.Status = "skipped"
+        case strings.Contains(message, "log file created"):
+                entry.Status = "created"
+        case strings.Contains(message, "error") || strings.Contains(message, "fail"):
+                entry.Status = "failed"
+        default:
+                entry.Status = "info"
+        }
+        return entry
+}
+
 // ParseCrontab parses crontab content into CronJob structs
 func ParseCrontab(content string) ([]CronJob, error) {
         var jobs []CronJob
         scanner := bufio.NewScanner(strings.NewReader(content))
 
         var currentDescription string
+        var currentTags []string
+        var env []string
+        var currentDisabled bool
         commentRegex := regexp.MustCompile(`^\s*#\s*(.*)$`)
+        metaRegex := regexp.MustCompile(`^tuicron:\s*tags=(.*)$`)
+        disabledRegex := regexp.MustCompile(`^tuicron:\s*disabled$`)
+        envRegex := regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
         cronRegex := regexp.MustCompile(`^\s*([^\s]+\s+[^\s]+\s+[^\s]+\s+[^\s]+\s+[^\s]+)\s+(.+)$`)
 
         for scanner.Scan() {
@@ -472,22 +759,37 @@ func ParseCrontab(content string) ([]CronJob, error) {
                         continue
                 }
 
-                // Check if it's a comment (potential description)
+                // Check if it's a comment (potential description or tuicron metadata).
+                // A disabled job is the commented out line after its marker.
                 if matches := commentRegex.FindStringSubmatch(line); matches != nil {
-                        if !strings.Contains(strings.ToLower(matches[1]), "cron") {
-                                currentDescription = matches[1]
+                        if currentDisabled {
+                                line = matches[1]
+                        } else {
+                                if meta := metaRegex.FindStringSubmatch(matches[1]); meta != nil {
+                                        currentTags = ParseTags(meta[1])
+                                } else if disabledRegex.MatchString(matches[1]) {
+                                        currentDisabled = true
+                                } else if !strings.Contains(strings.ToLower(matches[1]), "cron") {
+                                        currentDescription = matches[1]
+                                }
+                                continue
                         }
+                }
+
+                // Check if it's an environment variable, which applies to every job below it
+                if matches := envRegex.FindStringSubmatch(line); matches != nil && !currentDisabled {
+                        env = setEnv(env, matches[1], matches[2])
                         continue
                 }
 
                 // Check if it's a cron job
                 if matches := cronRegex.FindStringSubmatch(line); matches != nil {
                         expression := matches[1]
-                        fullCommand := matches[2]
+                        fullCommand, stdin := SplitCronCommand(matches[2])
                         
                         // Extract clean command and log file from full command
-                        cleanCommand := StripLoggingFromCommand(fullCommand)
-                        logFile := ExtractLogFileFromCommand(fullCommand)
+                        wrappers := parseWrappers(fullCommand)
+                        logFile := wrappers.LogFile
 
                         nextRun, err := GetNextRunTime(expression)
                         if err != nil {
@@ -498,22 +800,184 @@ func ParseCrontab(content string) ([]CronJob, error) {
                         job := CronJob{
                                 Description: currentDescription,
                                 Expression:  expression,
-                                Command:     cleanCommand,
+                                Command:     wrappers.Command,
                                 LogFile:     logFile,
                                 NextRun:     nextRun,
                                 LastRun:     GetLastRunFromLogFile(logFile),
+                                Tags:        currentTags,
+                                Env:         append([]string(nil), env...),
+                                NoOverlap:   wrappers.LockFile != "",
+                                Timeout:     wrappers.Timeout,
+                                KillAfter:   wrappers.KillAfter,
+                                Retry:       wrappers.Retry,
+                                Notify:      wrappers.Notify,
+                                Stdin:       stdin,
+                                Disabled:    currentDisabled,
+                        }
+                        if job.Disabled {
+                                job.NextRun = time.Time{}
                         }
+                        job.LastStatus = GetJobStatus(job)
 
                         jobs = append(jobs, job)
                         currentDescription = "" // Reset description
+                        currentTags = nil
                 }
+                currentDisabled = false
         }
 
         return jobs, nil
 }
 
-// WriteCrontab writes the cron jobs back to the user's crontab
+// SetJobDisabled disables or re-enables a job, updating its next run and status
+func SetJobDisabled(job *CronJob, disabled bool) {
+        job.Disabled = disabled
+        job.NextRun = time.Time{}
+        if !disabled {
+                job.NextRun, _ = GetNextRunTime(job.Expression)
+        }
+        job.LastStatus = GetJobStatus(*job)
+}
+
+// setEnv sets NAME=value in a list of environment assignments, replacing any
+// earlier assignment to the same name
+func setEnv(env []string, name, value string) []string {
+        assignment := name + "=" + value
+        for i, existing := range env {
+                if strings.HasPrefix(existing, name+"=") {
+                        env[i] = assignment
+                        return env
+                }
+        }
+        return append(env, assignment)
+}
+
+// CrontabLine returns the exact line tuicron installs for a job. Every job is
+// run through `tuicron exec`, so there must be a binary for cron to call.
+func CrontabLine(job CronJob) (string, error) {
+        runner := RunnerPath()
+        if runner == "" {
+                return "", fmt.Errorf("can't find the tuicron binary for cron to run jobs with, install it on your PATH first")
+        }
+        return fmt.Sprintf("%s %s", job.Expression, CronCommandField(AddRunnerToCommand(runner, job), job.Stdin)), nil
+}
+
+// EscapeCronPercent escapes every % so cron passes it through instead of
+// treating it as a newline
+func EscapeCronPercent(s string) string {
+        return strings.ReplaceAll(s, "%", `\%`)
+}
+
+// StripPercentEscapes turns a \% typed out of crontab habit back into a plain
+// %, since EscapeCronPercent adds the backslash when the line is written. Cron
+// has no way to pass an odd run of backslashes followed by % through intact.
+func StripPercentEscapes(command string) string {
+        var b strings.Builder
+        backslashes := 0
+        for _, r := range command {
+                if r == '%' && backslashes%2 == 1 {
+                        out := b.String()
+                        b.Reset()
+                        b.WriteString(out[:len(out)-1])
+                }
+                if r == '\\' {
+                        backslashes++
+                } else {
+                        backslashes = 0
+                }
+                b.WriteRune(r)
+        }
+        return b.String()
+}
+
+// CronCommandField builds the command field of a crontab line. The command's
+// % signs are escaped, and any stdin follows a bare % with its newlines
+// written as further bare % signs.
+func CronCommandField(command, stdin string) string {
+        field := EscapeCronPercent(command)
+        if stdin != "" {
+                lines := strings.Split(stdin, "\n")
+                for i := range lines {
+                        lines[i] = EscapeCronPercent(lines[i])
+                }
+                field += "%" + strings.Join(lines, "%")
+        }
+        return field
+}
+
+// SplitCronCommand reads the command field of a crontab line the way cron
+// does: \% is a literal %, the first bare % ends the command, and the rest is
+// stdin with each further bare % as a newline. A backslash before anything
+// else is kept.
+func SplitCronCommand(field string) (string, string) {
+        var command strings.Builder
+        escaped := false
+        stdinStart := -1
+        for i, r := range field {
+                if escaped {
+                        if r != '%' {
+                                command.WriteRune('\\')
+                        }
+                        command.WriteRune(r)
+                        escaped = false
+                } else if r == '\\' {
+                        escaped = true
+                } else if r == '%' {
+                        stdinStart = i + 1
+                        break
+                } else {
+                        command.WriteRune(r)
+                }
+        }
+        if escaped {
+                command.WriteRune('\\')
+        }
+        if stdinStart < 0 {
+                return command.String(), ""
+        }
+
+        // In stdin a backslash that follows another one escapes too, so \\%
+        // is a backslash and a %
+        var stdin strings.Builder
+        escaped = false
+        for _, r := range field[stdinStart:] {
+                if escaped && r != '%' {
+                        stdin.WriteRune('\\')
+                }
+                if !escaped && r == '%' {
+                        r = '\n'
+                }
+                escaped = r == '\\'
+                if !escaped {
+                        stdin.WriteRune(r)
+                }
+        }
+        if escaped {
+                stdin.WriteRune('\\')
+        }
+        return command.String(), stdin.String()
+}
+
+// CronStdin returns the input cron gives a job with the given stdin text,
+// which always ends in a newline when there is any
+func CronStdin(stdin string) string {
+        if stdin != "" && !strings.HasSuffix(stdin, "\n") {
+                stdin += "\n"
+        }
+        return stdin
+}
+
+// WriteCrontab writes the cron jobs back to the user's crontab. Jobs from
+// other sources, such as systemd timers, are left out.
 func WriteCrontab(jobs []CronJob) error {
+        var crontabJobs []CronJob
+        for _, job := range jobs {
+                if job.Source == SourceCrontab {
+                        crontabJobs = append(crontabJobs, job)
+                }
+        }
+        jobs = crontabJobs
+
         // Create backup first
         if err := BackupCrontab(); err != nil {
                 return fmt.Errorf("failed to backup crontab: %v", err)
@@ -537,19 +1001,33 @@ func WriteCrontab(jobs []CronJob) error {
         content.WriteString("# Managed by tuicron\n")
         content.WriteString(fmt.Sprintf("# Generated on %s\n\n", time.Now().Format("2006-01-02 15:04:05")))
 
+        // Environment assignments already written, so each one is only repeated
+        // when a job needs a different value
+        written := map[string]string{}
+
         for _, job := range jobs {
+                for _, assignment := range job.Env {
+                        name := strings.SplitN(assignment, "=", 2)[0]
+                        if written[name] != assignment {
+                                content.WriteString(assignment + "\n")
+                                written[name] = assignment
+                        }
+                }
+
                 if job.Description != "" {
                         content.WriteString(fmt.Sprintf("# %s\n", job.Description))
                 }
-                
-                // Add logging to the command only if log file is specified
-                var finalCommand string
-                if job.LogFile != "" {
-                        finalCommand = AddLoggingToCommand(job.Command, job.LogFile)
-                } else {
-                        finalCommand = job.Command
+                if len(job.Tags) > 0 {
+                        content.WriteString(fmt.Sprintf("# tuicron: tags=%s\n", strings.Join(job.Tags, ",")))
+                }
+                if job.Disabled {
+                        content.WriteString("# tuicron: disabled\n# ")
+                }
+                line, err := CrontabLine(job)
+                if err != nil {
+                        return err
                 }
-                content.WriteString(fmt.Sprintf("%s %s\n\n", job.Expression, finalCommand))
+                content.WriteString(line + "\n\n")
         }
 
         // Write to temporary file first
@@ -0,0 +1,204 @@
+package main
+
+import (
+        "os"
+        "path/filepath"
+        "strings"
+        "testing"
+        "time"
+        "unicode/utf8"
+)
+
+// cronCanExpress reports whether cron can pass a command and its stdin
+// through intact. Like a \% in the command, which tuicron strips before
+// saving, a backslash right before the % that ends the command or a line of
+// stdin always escapes it.
+func cronCanExpress(command, stdin string) bool {
+        if !utf8.ValidString(command) || !utf8.ValidString(stdin) {
+                return false
+        }
+        if stdin == "" {
+                return true
+        }
+        trailing := len(command) - len(strings.TrimRight(command, `\`))
+        if trailing%2 == 1 {
+                return false
+        }
+        lines := strings.Split(stdin, "\n")
+        for _, line := range lines[:len(lines)-1] {
+                if strings.HasSuffix(line, `\`) {
+                        return false
+                }
+        }
+        return true
+}
+
+func FuzzCronCommandField(f *testing.F) {
+        seeds := []struct {
+                command string
+                stdin   string
+        }{
+                {"date +%F", ""},
+                {`echo \%`, ""},
+                {`printf '%s\n' done`, "100%"},
+                {`echo trailing\`, ""},
+                {`echo trailing\\`, "input"},
+                {"mail -s report root", "line one\nline two"},
+                {"cat", `50\% off`},
+                {"cat", "%"},
+                {"%", "%%"},
+                {"cat", "ends with a backslash\\"},
+                {"cat", "\n\n"},
+        }
+        for _, seed := range seeds {
+                f.Add(seed.command, seed.stdin)
+        }
+
+        f.Fuzz(func(t *testing.T, command, stdin string) {
+                command = StripPercentEscapes(command)
+                if !cronCanExpress(command, stdin) {
+                        t.Skip()
+                }
+                field := CronCommandField(command, stdin)
+                gotCommand, gotStdin := SplitCronCommand(field)
+                if gotCommand != command || gotStdin != stdin {
+                        t.Errorf("CronCommandField(%q, %q) = %q, read back as (%q, %q)", command, stdin, field, gotCommand, gotStdin)
+                }
+        })
+}
+
+// useRunner puts a stand-in tuicron binary on PATH for CrontabLine to find
+func useRunner(t *testing.T) {
+        dir := t.TempDir()
+        if err := os.WriteFile(filepath.Join(dir, "tuicron"), []byte("#!/bin/sh\n"), 0755); err != nil {
+                t.Fatal(err)
+        }
+        t.Setenv("PATH", dir)
+        t.Setenv("HOME", t.TempDir())
+}
+
+// roundTrip installs a job's line and reads it back the way loading does
+func roundTrip(t *testing.T, job CronJob) CronJob {
+        line, err := CrontabLine(job)
+        if err != nil {
+                t.Fatalf("CrontabLine: %v", err)
+        }
+        jobs, err := ParseCrontab(line + "\n")
+        if err != nil || len(jobs) != 1 {
+                t.Fatalf("ParseCrontab(%q) = %d jobs, %v", line, len(jobs), err)
+        }
+        return jobs[0]
+}
+
+func TestCrontabLineRoundTrip(t *testing.T) {
+        useRunner(t)
+        jobs := []CronJob{
+                {Expression: "* * * * *", Command: "echo hello"},
+                {Expression: "0 2 * * *", Command: "echo hi >> /tmp/out 2>&1"},
+                {
+                        Expression: "30 2 * * 1-5",
+                        Command:    `{ echo "a; } >> b" 2>&1; } >> /tmp/out 2>&1; date +%F`,
+                        LogFile:    "my-job.v2",
+                        NoOverlap:  true,
+                        Timeout:    90 * time.Second,
+                        KillAfter:  defaultKillAfter,
+                        Retry:      RetryPolicy{Attempts: 3, Delay: 30 * time.Second, Backoff: 2},
+                        Notify:     `mail -s "backup failed" root`,
+                        Stdin:      "line one\n100%",
+                },
+        }
+        for _, job := range jobs {
+                got := roundTrip(t, job)
+                if got.Expression != job.Expression || got.Command != job.Command || got.LogFile != job.LogFile ||
+                        got.NoOverlap != job.NoOverlap || got.Timeout != job.Timeout || got.KillAfter != job.KillAfter ||
+                        got.Retry != job.Retry || got.Notify != job.Notify || got.Stdin != job.Stdin {
+                        t.Errorf("round trip changed the job:\n got %+v\nwant %+v", got, job)
+                }
+        }
+}
+
+func TestParseCrontabKeepsRedirects(t *testing.T) {
+        jobs, err := ParseCrontab("0 2 * * * echo hi >> /tmp/out 2>&1\n")
+        if err != nil || len(jobs) != 1 || jobs[0].Command != "echo hi >> /tmp/out 2>&1" || jobs[0].LogFile != "" {
+                t.Errorf("ParseCrontab = %+v, %v", jobs, err)
+        }
+}
+
+func TestCrontabLineWithoutRunner(t *testing.T) {
+        t.Setenv("PATH", t.TempDir())
+        if line, err := CrontabLine(CronJob{Expression: "* * * * *", Command: "true"}); err == nil {
+                t.Errorf("CrontabLine without a tuicron binary = %q, want an error", line)
+        }
+}
+
+func TestValidateLogFileName(t *testing.T) {
+        valid := []string{"backup", "my-job.v2", "db_dump-2024.01", "9am"}
+        for _, name := range valid {
+                if err := ValidateLogFileName(name); err != nil {
+                        t.Errorf("ValidateLogFileName(%q) = %v, want nil", name, err)
+                }
+        }
+
+        invalid := []string{"", "my job", "job;rm -rf ~", "logs/job", "../job", "job..", "..", ".hidden", "-job", "job$HOME", "job'x"}
+        for _, name := range invalid {
+                if err := ValidateLogFileName(name); err == nil {
+                        t.Errorf("ValidateLogFileName(%q) = nil, want an error", name)
+                }
+        }
+}
+
+func TestLogFileNameRoundTrip(t *testing.T) {
+        useRunner(t)
+        for _, name := range []string{"backup", "my-job.v2", "db_dump-2024.01"} {
+                job := roundTrip(t, CronJob{Expression: "0 3 * * *", Command: "echo done", LogFile: name})
+                if job.LogFile != name {
+                        t.Errorf("log file %q read back as %q", name, job.LogFile)
+                }
+        }
+
+        // Lines written by older versions name the log file in a shell redirect
+        line := `0 3 * * * { printf '\%s - Starting job\n' "$(date '+\%Y-\%m-\%d \%H:\%M:\%S')" && echo done; } >> /home/user/.cron_history/my-job.v2.log 2>&1`
+        jobs, err := ParseCrontab(line + "\n")
+        if err != nil || len(jobs) != 1 || jobs[0].LogFile != "my-job.v2" || jobs[0].Command != "echo done" {
+                t.Errorf("ParseCrontab(%q) = %+v, %v", line, jobs, err)
+        }
+}
+
+func TestNormalizeSunday(t *testing.T) {
+        tests := []struct {
+                expr string
+                want string
+        }{
+                {"0 0 * * 7", "0 0 * * 0"},
+                {"0 0 * * 5-7", "0 0 * * 0,5,6"},
+                {"0 0 * * 1,7", "0 0 * * 1,0"},
+                {"0 0 * * 1-7/2", "0 0 * * 0,1,3,5"},
+                {"0 0 * * 0-7", "0 0 * * 0-6"},
+                {"0 0 * * 1-5", "0 0 * * 1-5"},
+                {"0 0 * * */2", "0 0 * * */2"},
+                {"0 0 * * MON-FRI", "0 0 * * MON-FRI"},
+                {"@weekly", "@weekly"},
+        }
+        for _, tt := range tests {
+                if got := normalizeSunday(tt.expr); got != tt.want {
+                        t.Errorf("normalizeSunday(%q) = %q, want %q", tt.expr, got, tt.want)
+                }
+        }
+}
+
+func TestNextRunOnSundaySeven(t *testing.T) {
+        from := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.Local) // a Monday
+        times, err := GetNextRunTimes("0 9 * * 5-7", from, 3)
+        if err != nil {
+                t.Fatal(err)
+        }
+        want := []time.Weekday{time.Friday, time.Saturday, time.Sunday}
+        for i, next := range times {
+                if next.Weekday() != want[i] {
+                        t.Errorf("run %d is on %s, want %s", i+1, next.Weekday(), want[i])
+                }
+        }
+        if len(times) != len(want) {
+                t.Errorf("got %d runs, want %d", len(times), len(want))
+        }
+}
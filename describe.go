@@ -0,0 +1,457 @@
+package main
+
+import (
+        "fmt"
+        "sort"
+        "strconv"
+        "strings"
+)
+
+// cronRange is one comma separated item of a cron field, e.g. "5", "1-5",
+// "*/15" or "10-50/10". Single values have Start == End.
+type cronRange struct {
+        Start int
+        End   int
+        Step  int  // 0 when no step was given
+        Every bool // Written as * or ?
+        Open  bool // Written as "a/n", which runs from a to the field maximum
+}
+
+// cronField is a parsed cron field made of one or more ranges
+type cronField struct {
+        Ranges []cronRange
+}
+
+// cronAST is a parsed five-field cron expression
+type cronAST struct {
+        Minute     cronField
+        Hour       cronField
+        DayOfMonth cronField
+        Month      cronField
+        DayOfWeek  cronField
+}
+
+// fieldBounds holds the allowed values and names for one cron field
+type fieldBounds struct {
+        Name  string
+        Min   int
+        Max   int
+        Names map[string]int
+}
+
+var (
+        minuteBounds = fieldBounds{Name: "minute", Min: 0, Max: 59}
+        hourBounds   = fieldBounds{Name: "hour", Min: 0, Max: 23}
+        domBounds    = fieldBounds{Name: "day of month", Min: 1, Max: 31}
+        monthBounds  = fieldBounds{Name: "month", Min: 1, Max: 12, Names: map[string]int{
+                "JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+                "JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+        }}
+        dowBounds = fieldBounds{Name: "day of week", Min: 0, Max: 7, Names: map[string]int{
+                "SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+        }}
+)
+
+// cronDescriptors maps the @ shorthands to their five-field equivalent
+var cronDescriptors = map[string]string{
+        "@yearly":   "0 0 1 1 *",
+        "@annually": "0 0 1 1 *",
+        "@monthly":  "0 0 1 * *",
+        "@weekly":   "0 0 * * 0",
+        "@daily":    "0 0 * * *",
+        "@midnight": "0 0 * * *",
+        "@hourly":   "0 * * * *",
+}
+
+var monthNames = []string{"", "January", "February", "March", "April", "May", "June",
+        "July", "August", "September", "October", "November", "December"}
+
+var dayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// parseCronAST parses a five-field cron expression or @ descriptor
+func parseCronAST(expr string) (cronAST, error) {
+        expr = strings.TrimSpace(expr)
+        if expanded, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+                expr = expanded
+        }
+
+        parts := strings.Fields(expr)
+        if len(parts) != 5 {
+                return cronAST{}, fmt.Errorf("expected 5 fields, found %d", len(parts))
+        }
+
+        var ast cronAST
+        targets := []*cronField{&ast.Minute, &ast.Hour, &ast.DayOfMonth, &ast.Month, &ast.DayOfWeek}
+        bounds := []fieldBounds{minuteBounds, hourBounds, domBounds, monthBounds, dowBounds}
+        for i, part := range parts {
+                field, err := parseCronField(part, bounds[i])
+                if err != nil {
+                        return cronAST{}, err
+                }
+                *targets[i] = field
+        }
+        return ast, nil
+}
+
+// parseCronField parses one field of a cron expression
+func parseCronField(value string, bounds fieldBounds) (cronField, error) {
+        var field cronField
+        for _, item := range strings.Split(value, ",") {
+                r, err := parseCronRange(item, bounds)
+                if err != nil {
+                        return cronField{}, err
+                }
+                field.Ranges = append(field.Ranges, r)
+        }
+        return field, nil
+}
+
+// parseCronRange parses a single list item such as "*/5", "MON-FRI" or "10-50/10"
+func parseCronRange(item string, bounds fieldBounds) (cronRange, error) {
+        base, stepText, hasStep := strings.Cut(item, "/")
+        r := cronRange{}
+
+        if hasStep {
+                step, err := strconv.Atoi(stepText)
+                if err != nil || step <= 0 {
+                        return r, fmt.Errorf("invalid step %q in %s field", stepText, bounds.Name)
+                }
+                r.Step = step
+        }
+
+        switch {
+        case base == "*" || base == "?":
+                r.Every = true
+                r.Start, r.End = bounds.Min, bounds.Max
+        case strings.Contains(base, "-"):
+                startText, endText, _ := strings.Cut(base, "-")
+                start, err := parseCronValue(startText, bounds)
+                if err != nil {
+                        return r, err
+                }
+                end, err := parseCronValue(endText, bounds)
+                if err != nil {
+                        return r, err
+                }
+                if start > end {
+                        return r, fmt.Errorf("range %s is backwards in %s field", base, bounds.Name)
+                }
+                r.Start, r.End = start, end
+        default:
+                value, err := parseCronValue(base, bounds)
+                if err != nil {
+                        return r, err
+                }
+                r.Start, r.End = value, value
+                if hasStep {
+                        r.End = bounds.Max
+                        r.Open = true
+                }
+        }
+
+        return r, nil
+}
+
+// parseCronValue parses a number or name and checks it's within bounds
+func parseCronValue(text string, bounds fieldBounds) (int, error) {
+        if value, ok := bounds.Names[strings.ToUpper(text)]; ok {
+                return value, nil
+        }
+        value, err := strconv.Atoi(text)
+        if err != nil {
+                return 0, fmt.Errorf("invalid value %q in %s field", text, bounds.Name)
+        }
+        if value < bounds.Min || value > bounds.Max {
+                return 0, fmt.Errorf("%s value %d out of range %d-%d", bounds.Name, value, bounds.Min, bounds.Max)
+        }
+        return value, nil
+}
+
+// IsAny reports whether the field matches every value, i.e. a bare "*"
+func (f cronField) IsAny() bool {
+        return len(f.Ranges) == 1 && f.Ranges[0].Every && f.Ranges[0].Step <= 1
+}
+
+// Singles returns the field's values if it is a plain list of values
+func (f cronField) Singles() ([]int, bool) {
+        var values []int
+        for _, r := range f.Ranges {
+                if r.Start != r.End || r.Step > 0 {
+                        return nil, false
+                }
+                values = append(values, r.Start)
+        }
+        return values, true
+}
+
+// single returns the only range in the field, if there is exactly one
+func (f cronField) single() (cronRange, bool) {
+        if len(f.Ranges) != 1 {
+                return cronRange{}, false
+        }
+        return f.Ranges[0], true
+}
+
+// ParseCronExpression converts a cron expression to human-readable text
+func ParseCronExpression(expr string) string {
+        ast, err := parseCronAST(expr)
+        if err != nil {
+                return "Invalid cron expression"
+        }
+        return describeCronAST(ast)
+}
+
+// describeCronAST builds an English description from a parsed expression
+func describeCronAST(ast cronAST) string {
+        segments := []string{describeTime(ast.Minute, ast.Hour)}
+
+        domAny := ast.DayOfMonth.IsAny()
+        dowAny := ast.DayOfWeek.IsAny()
+        switch {
+        case !domAny && !dowAny:
+                // Cron runs when either day field matches, so both sets of days are included
+                segments = append(segments, describeDayOfMonth(ast.DayOfMonth)+
+                        " as well as on every "+joinList(renderRanges(expandWeekdaySteps(ast.DayOfWeek), dayName), "and"))
+        case !domAny:
+                segments = append(segments, describeDayOfMonth(ast.DayOfMonth))
+        case !dowAny:
+                segments = append(segments, describeDayOfWeek(ast.DayOfWeek))
+        }
+
+        if !ast.Month.IsAny() {
+                segments = append(segments, describeMonth(ast.Month))
+        }
+
+        description := strings.Join(segments, ", ")
+        return strings.ToUpper(description[:1]) + description[1:]
+}
+
+// describeTime describes the minute and hour fields together
+func describeTime(minute, hour cronField) string {
+        minutes, minutesOK := minute.Singles()
+        hours, hoursOK := hour.Singles()
+
+        // A handful of exact times reads best as a list of clock times
+        if minutesOK && hoursOK && len(minutes)*len(hours) <= 6 {
+                var times []string
+                sort.Ints(hours)
+                sort.Ints(minutes)
+                for _, h := range hours {
+                        for _, m := range minutes {
+                                times = append(times, formatClock(h, m))
+                        }
+                }
+                return "at " + joinList(times, "and")
+        }
+
+        if hour.IsAny() {
+                if minutesOK && len(minutes) == 1 && minutes[0] == 0 {
+                        return "every hour"
+                }
+                return describeMinute(minute)
+        }
+
+        // "0 */2" and "0 9-17/2" are simply every n hours
+        if r, ok := hour.single(); ok && r.Step > 1 && minutesOK && len(minutes) == 1 && minutes[0] == 0 {
+                return fmt.Sprintf("every %d hours", r.Step) + hourRangeSuffix(r)
+        }
+
+        // "0 9-17" runs on the hour, every hour in the range
+        if r, ok := hour.single(); ok && r.Step == 0 && r.Start != r.End && minutesOK && len(minutes) == 1 {
+                if minutes[0] == 0 {
+                        return fmt.Sprintf("every hour from %s through %s", formatClock(r.Start, 0), formatClock(r.End, 0))
+                }
+                return fmt.Sprintf("at %d minutes past the hour, between %s and %s",
+                        minutes[0], formatClock(r.Start, 0), formatClock(r.End, 59))
+        }
+
+        return describeMinute(minute) + ", " + describeHour(hour)
+}
+
+// describeMinute describes the minute field on its own
+func describeMinute(minute cronField) string {
+        if minute.IsAny() {
+                return "every minute"
+        }
+
+        if r, ok := minute.single(); ok {
+                switch {
+                case r.Every:
+                        return fmt.Sprintf("every %d minutes", r.Step)
+                case r.Open:
+                        return fmt.Sprintf("every %d minutes, starting at %d minutes past the hour", r.Step, r.Start)
+                case r.Step > 0:
+                        return fmt.Sprintf("every %d minutes, minutes %d through %d past the hour", r.Step, r.Start, r.End)
+                case r.Start != r.End:
+                        return fmt.Sprintf("every minute, minutes %d through %d past the hour", r.Start, r.End)
+                case r.Start == 1:
+                        return "at 1 minute past the hour"
+                }
+        }
+
+        return "at " + joinList(renderRanges(minute, strconv.Itoa), "and") + " minutes past the hour"
+}
+
+// describeHour describes the hour field as a qualifier of the minute field
+func describeHour(hour cronField) string {
+        if r, ok := hour.single(); ok {
+                switch {
+                case r.Every:
+                        return fmt.Sprintf("every %d hours", r.Step)
+                case r.Open:
+                        return fmt.Sprintf("every %d hours, starting at %s", r.Step, formatClock(r.Start, 0))
+                case r.Step > 0:
+                        return fmt.Sprintf("every %d hours", r.Step) + hourRangeSuffix(r)
+                default:
+                        return fmt.Sprintf("between %s and %s", formatClock(r.Start, 0), formatClock(r.End, 59))
+                }
+        }
+
+        if hours, ok := hour.Singles(); ok {
+                var labels []string
+                for _, h := range hours {
+                        labels = append(labels, formatHour(h))
+                }
+                return "during the " + joinList(labels, "and") + " hours"
+        }
+
+        return "during hours " + joinList(renderRanges(hour, formatHour), "and")
+}
+
+// hourRangeSuffix describes the bounds of a stepped hour range
+func hourRangeSuffix(r cronRange) string {
+        switch {
+        case r.Every:
+                return ""
+        case r.Open:
+                return ", starting at " + formatClock(r.Start, 0)
+        default:
+                return fmt.Sprintf(", between %s and %s", formatClock(r.Start, 0), formatClock(r.End, 59))
+        }
+}
+
+// describeDayOfMonth describes the day of month field
+func describeDayOfMonth(dom cronField) string {
+        if r, ok := dom.single(); ok {
+                switch {
+                case r.Every:
+                        return fmt.Sprintf("every %d days", r.Step)
+                case r.Step > 0:
+                        return fmt.Sprintf("every %d days, between day %d and %d of the month", r.Step, r.Start, r.End)
+                case r.Start != r.End:
+                        return fmt.Sprintf("between day %d and %d of the month", r.Start, r.End)
+                default:
+                        return fmt.Sprintf("on day %d of the month", r.Start)
+                }
+        }
+        return "on days " + joinList(renderRanges(dom, strconv.Itoa), "and") + " of the month"
+}
+
+// describeDayOfWeek describes the day of week field
+func describeDayOfWeek(dow cronField) string {
+        dow = expandWeekdaySteps(dow)
+        if r, ok := dow.single(); ok && r.Start != r.End {
+                return fmt.Sprintf("%s through %s", dayName(r.Start), dayName(r.End))
+        }
+        return "only on " + joinList(renderRanges(dow, dayName), "and")
+}
+
+// expandWeekdaySteps lists the days a stepped day of week range picks out,
+// since a week is too short for "every 2nd day" to say which days are meant.
+// 7 is Sunday again, so it isn't listed twice.
+func expandWeekdaySteps(dow cronField) cronField {
+        var expanded cronField
+        seen := map[int]bool{}
+        for _, r := range dow.Ranges {
+                if r.Step == 0 {
+                        expanded.Ranges = append(expanded.Ranges, r)
+                        continue
+                }
+                for day := r.Start; day <= r.End; day += r.Step {
+                        if !seen[day%7] {
+                                seen[day%7] = true
+                                expanded.Ranges = append(expanded.Ranges, cronRange{Start: day % 7, End: day % 7})
+                        }
+                }
+        }
+        return expanded
+}
+
+// describeMonth describes the month field
+func describeMonth(month cronField) string {
+        if r, ok := month.single(); ok {
+                switch {
+                case r.Every:
+                        return fmt.Sprintf("every %d months", r.Step)
+                case r.Step > 0:
+                        return fmt.Sprintf("every %d months, %s through %s", r.Step, monthName(r.Start), monthName(r.End))
+                case r.Start != r.End:
+                        return fmt.Sprintf("%s through %s", monthName(r.Start), monthName(r.End))
+                }
+        }
+        return "only in " + joinList(renderRanges(month, monthName), "and")
+}
+
+// renderRanges renders each range of a field using name for individual values
+func renderRanges(field cronField, name func(int) string) []string {
+        var items []string
+        for _, r := range field.Ranges {
+                switch {
+                case r.Step > 0 && r.Every:
+                        items = append(items, fmt.Sprintf("every %s", ordinal(r.Step)))
+                case r.Step > 0:
+                        items = append(items, fmt.Sprintf("every %s from %s through %s", ordinal(r.Step), name(r.Start), name(r.End)))
+                case r.Start != r.End:
+                        items = append(items, fmt.Sprintf("%s through %s", name(r.Start), name(r.End)))
+                default:
+                        items = append(items, name(r.Start))
+                }
+        }
+        return items
+}
+
+// joinList joins items as "a, b and c" using the given conjunction
+func joinList(items []string, conjunction string) string {
+        switch len(items) {
+        case 0:
+                return ""
+        case 1:
+                return items[0]
+        }
+        return strings.Join(items[:len(items)-1], ", ") + " " + conjunction + " " + items[len(items)-1]
+}
+
+// formatClock formats an hour and minute as 24-hour clock time, e.g. "09:30"
+func formatClock(hour, minute int) string {
+        return fmt.Sprintf("%02d:%02d", hour, minute)
+}
+
+// formatHour formats an hour on its own as "09:00"
+func formatHour(hour int) string {
+        return formatClock(hour, 0)
+}
+
+// dayName returns the weekday name for a cron day of week, where 0 and 7 are Sunday
+func dayName(day int) string {
+        return dayNames[day%7]
+}
+
+// monthName returns the name of a month number
+func monthName(month int) string {
+        return monthNames[month]
+}
+
+// ordinal returns "2nd", "3rd", "10th" and so on
+func ordinal(n int) string {
+        suffix := "th"
+        switch {
+        case n%100 >= 11 && n%100 <= 13:
+        case n%10 == 1:
+                suffix = "st"
+        case n%10 == 2:
+                suffix = "nd"
+        case n%10 == 3:
+                suffix = "rd"
+        }
+        return fmt.Sprintf("%d%s", n, suffix)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+        "strings"
+        "testing"
+)
+
+func TestParseCronExpression(t *testing.T) {
+        tests := []struct {
+                expr string
+                want string
+        }{
+                // Minutes and hours
+                {"* * * * *", "Every minute"},
+                {"*/5 * * * *", "Every 5 minutes"},
+                {"0 * * * *", "Every hour"},
+                {"1 * * * *", "At 1 minute past the hour"},
+                {"15,45 * * * *", "At 15 and 45 minutes past the hour"},
+                {"1-5 * * * *", "Every minute, minutes 1 through 5 past the hour"},
+                {"5/15 * * * *", "Every 15 minutes, starting at 5 minutes past the hour"},
+                {"10-50/10 * * * *", "Every 10 minutes, minutes 10 through 50 past the hour"},
+                {"0 9 * * *", "At 09:00"},
+                {"1 0 * * *", "At 00:01"},
+                {"0 9,17 * * *", "At 09:00 and 17:00"},
+                {"0,30 9 * * *", "At 09:00 and 09:30"},
+                {"0 8,12,18 * * *", "At 08:00, 12:00 and 18:00"},
+                {"0 9-17 * * *", "Every hour from 09:00 through 17:00"},
+                {"30 9-17 * * *", "At 30 minutes past the hour, between 09:00 and 17:59"},
+                {"0 */2 * * *", "Every 2 hours"},
+                {"0 4/6 * * *", "Every 6 hours, starting at 04:00"},
+                {"0 9-17/2 * * *", "Every 2 hours, between 09:00 and 17:59"},
+                {"*/15 */2 * * *", "Every 15 minutes, every 2 hours"},
+                {"*/10 9-17 * * 1-5", "Every 10 minutes, between 09:00 and 17:59, Monday through Friday"},
+
+                // Days of the week
+                {"30 9 * * 1-5", "At 09:30, Monday through Friday"},
+                {"0 22 * * MON-FRI", "At 22:00, Monday through Friday"},
+                {"5 4 * * sun", "At 04:05, only on Sunday"},
+                {"0 0 * * 7", "At 00:00, only on Sunday"},
+                {"0 0 * * 0,6", "At 00:00, only on Sunday and Saturday"},
+                {"0 12 * * 1/2", "At 12:00, only on Monday, Wednesday, Friday and Sunday"},
+                {"0 0 * * */2", "At 00:00, only on Sunday, Tuesday, Thursday and Saturday"},
+                {"0 0 * * 1-7/2", "At 00:00, only on Monday, Wednesday, Friday and Sunday"},
+
+                // Days of the month and months
+                {"0 0 1-7 * *", "At 00:00, between day 1 and 7 of the month"},
+                {"0 0 */3 * *", "At 00:00, every 3 days"},
+                {"0 0 10-20/5 * *", "At 00:00, every 5 days, between day 10 and 20 of the month"},
+                {"0 0 1 1 *", "At 00:00, on day 1 of the month, only in January"},
+                {"0 0 1 */2 *", "At 00:00, on day 1 of the month, every 2 months"},
+                {"0 0 * JAN,JUL *", "At 00:00, only in January and July"},
+                {"0 0 * 1-6 *", "At 00:00, January through June"},
+
+                // Either day field matching
+                {"0 0 1 * MON", "At 00:00, on day 1 of the month as well as on every Monday"},
+                {"0 0 1,15 * MON,FRI", "At 00:00, on days 1 and 15 of the month as well as on every Monday and Friday"},
+                {"0 0 1 * 1-5", "At 00:00, on day 1 of the month as well as on every Monday through Friday"},
+
+                // Shorthands and errors
+                {"@daily", "At 00:00"},
+                {"@weekly", "At 00:00, only on Sunday"},
+                {"@hourly", "Every hour"},
+                {"0 0 L * *", "Invalid cron expression"},
+                {"60 * * * *", "Invalid cron expression"},
+                {"0 0 * *", "Invalid cron expression"},
+        }
+
+        for _, test := range tests {
+                if got := ParseCronExpression(test.expr); got != test.want {
+                        t.Errorf("ParseCronExpression(%q) = %q, want %q", test.expr, got, test.want)
+                }
+
+                // Whatever gets described has to be accepted when saved too
+                if strings.HasPrefix(test.expr, "@") {
+                        continue
+                }
+                valid := test.want != "Invalid cron expression"
+                if err := ValidateCronExpression(test.expr); (err == nil) != valid {
+                        t.Errorf("ValidateCronExpression(%q) = %v, but the description is %q", test.expr, err, test.want)
+                }
+        }
+}
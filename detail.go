@@ -0,0 +1,188 @@
+package main
+
+import (
+        "fmt"
+        "strings"
+        "time"
+
+        "github.com/charmbracelet/lipgloss"
+)
+
+const (
+        detailPaneWidth    = 60  // Width of the detail pane when shown beside the table
+        detailPaneHeight   = 25  // Lines reserved for the detail pane when shown below the table
+        sideBySideMinWidth = 150 // Terminal width needed to show the pane beside the table
+        detailRunCount     = 5   // Number of upcoming runs listed in the pane
+)
+
+var detailLabelStyle = lipgloss.NewStyle().
+        Foreground(lipgloss.Color("86")).
+        Bold(true)
+
+// detailsBeside reports whether the detail pane is drawn to the right of the table
+func (m Model) detailsBeside() bool {
+        return m.showDetails && m.width >= sideBySideMinWidth
+}
+
+// tableWidth returns the width available to the job table
+func (m Model) tableWidth() int {
+        if m.detailsBeside() {
+                return m.width - detailPaneWidth
+        }
+        return m.width
+}
+
+// tableHeight returns the number of rows available to the job table
+func (m Model) tableHeight() int {
+        height := m.height - 10
+        if total, _ := lintSummary(m.issues); total > 0 {
+                height-- // Room for the lint summary line
+        }
+        if m.showDetails && !m.detailsBeside() {
+                height -= detailPaneHeight
+        }
+        if height < 3 {
+                height = 3
+        }
+        return height
+}
+
+// resize applies the current terminal size to the table
+func (m *Model) resize() {
+        m.table.SetWidth(m.tableWidth() - tableChrome)
+        m.table.SetHeight(m.tableHeight())
+        m.updateTable()
+}
+
+// viewDetails renders the detail pane for the selected job
+func (m Model) viewDetails(width int) string {
+        index := m.selectedJobIndex()
+        if index < 0 {
+                return baseStyle.Width(width - 2).Render(helpStyle.Render("No job selected"))
+        }
+        job := m.jobs[index]
+
+        // Inner width left for values once the border, padding and labels are taken off
+        labelWidth := 11
+        valueWidth := width - 4 - labelWidth - 1
+        if valueWidth < 10 {
+                valueWidth = 10
+        }
+        valueStyle := lipgloss.NewStyle().Width(valueWidth)
+
+        var b strings.Builder
+        field := func(label, value string) {
+                b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+                        detailLabelStyle.Width(labelWidth+1).Render(label),
+                        valueStyle.Render(value)))
+                b.WriteString("\n")
+        }
+
+        description := job.Description
+        if description == "" {
+                description = "No description"
+        }
+        b.WriteString(titleStyle.Render(description))
+        b.WriteString("\n")
+
+        if index < len(m.issues) && len(m.issues[index]) > 0 {
+                var lines []string
+                for _, issue := range m.issues[index] {
+                        style := warningStyle
+                        if issue.Severity == LintError {
+                                style = errorStyle
+                        }
+                        lines = append(lines, style.Render(lintBadge+issue.Message))
+                }
+                field("Issues", strings.Join(lines, "\n"))
+        }
+
+        if job.Source == SourceSystem {
+                field("Source", systemSourceLabel(job)+", read-only")
+        }
+        field("Command", StripLoggingFromCommand(job.Command))
+        if job.Stdin != "" {
+                field("Stdin", job.Stdin)
+        }
+        var runs []string
+        if job.Source == SourceTimer {
+                field("Unit", job.TimerUnit+" → "+job.ServiceUnit)
+                field("Schedule", job.Expression)
+                if !job.NextRun.IsZero() {
+                        runs = append(runs, job.NextRun.Format("Mon Jan 2 2006, 15:04"))
+                }
+        } else {
+                field("Schedule", job.Expression+"\n"+cronDescStyle.Render(ParseCronExpression(job.Expression)))
+                if times, err := GetNextRunTimes(job.Expression, time.Now(), detailRunCount); err == nil {
+                        for _, t := range times {
+                                runs = append(runs, t.Format("Mon Jan 2 2006, 15:04"))
+                        }
+                }
+        }
+        if job.Disabled {
+                runs = []string{"Disabled, press x to enable"}
+        }
+        if len(runs) == 0 {
+                runs = append(runs, "Never")
+        }
+        field("Next runs", strings.Join(runs, "\n"))
+
+        lastRun := "Never"
+        if job.LogFile == "" && job.Source != SourceTimer {
+                lastRun = "Not logged"
+        } else if !job.LastRun.IsZero() {
+                lastRun = job.LastRun.Format("Mon Jan 2 2006, 15:04")
+        }
+        field("Last run", fmt.Sprintf("%s (%s)", lastRun, job.LastStatus))
+
+        logPath := "None"
+        if job.LogFile != "" {
+                logPath = GetLogFilePath(job.LogFile)
+        }
+        field("Log file", logPath)
+
+        overlap := "Allowed"
+        if job.NoOverlap {
+                overlap = "Prevented, lock " + GetLockFilePath(job)
+        }
+        field("Overlap", overlap)
+
+        timeout := "None"
+        if job.Timeout > 0 {
+                timeout = fmt.Sprintf("%s, killed %s later if still running", FormatTimeout(job.Timeout), FormatTimeout(job.KillAfter))
+        }
+        field("Timeout", timeout)
+        field("Retry", job.Retry.Describe())
+
+        notify := "None"
+        if job.Notify != "" {
+                notify = job.Notify
+        }
+        field("On failure", notify)
+
+        tags := "None"
+        if len(job.Tags) > 0 {
+                tags = strings.Join(job.Tags, ", ")
+        }
+        field("Tags", tags)
+
+        env := "None"
+        if len(job.Env) > 0 {
+                env = strings.Join(job.Env, "\n")
+        }
+        field("Env", env)
+
+        // Only the user's crontab has lines tuicron wrote
+        if job.Source == SourceCrontab {
+                if line, err := CrontabLine(job); err == nil {
+                        field("Installed", helpStyle.Render(line))
+                } else {
+                        field("Installed", errorStyle.Render(err.Error()))
+                }
+        }
+
+        return baseStyle.
+                Width(width - 2).
+                Padding(0, 1).
+                Render(strings.TrimRight(b.String(), "\n"))
+}
@@ -94,6 +94,8 @@ func (m Model) viewHelp() string {
         b.WriteString("• Consider timezone differences")
         b.WriteString("\n")
         b.WriteString("• Use >> /path/to/logfile 2>&1 for logging")
+        b.WriteString("\n")
+        b.WriteString("• Press ctrl+b in the edit form to build an expression with pickers")
         b.WriteString("\n\n")
 
         // Keybindings
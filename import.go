@@ -0,0 +1,353 @@
+package main
+
+import (
+        "fmt"
+        "os"
+        "path/filepath"
+        "strconv"
+        "strings"
+
+        "github.com/charmbracelet/bubbles/textinput"
+        "github.com/charmbracelet/bubbletea"
+        "github.com/charmbracelet/lipgloss"
+)
+
+// importCandidate is a job from an import file, and whether to bring it in
+type importCandidate struct {
+        Job      CronJob
+        Selected bool
+        Problem  string // Why it is left unchecked by default, such as being a duplicate
+}
+
+// readImportFile reads jobs from a plain crontab file or from a spec written
+// by `tuicron export`
+func readImportFile(path string) ([]CronJob, error) {
+        data, err := os.ReadFile(expandHome(path))
+        if err != nil {
+                return nil, err
+        }
+
+        // A spec always starts with its version, a crontab never parses as one
+        if spec, err := ReadSpec(data); err == nil {
+                jobs := make([]CronJob, 0, len(spec.Jobs))
+                for i, jobSpec := range spec.Jobs {
+                        job, err := jobSpec.Job()
+                        if err != nil {
+                                return nil, fmt.Errorf("job %d: %v", i+1, err)
+                        }
+                        jobs = append(jobs, job)
+                }
+                return jobs, nil
+        }
+
+        return ParseCrontab(string(data))
+}
+
+// expandHome replaces a leading ~/ with the user's home directory
+func expandHome(path string) string {
+        if strings.HasPrefix(path, "~/") {
+                if homeDir, err := os.UserHomeDir(); err == nil {
+                        return filepath.Join(homeDir, path[2:])
+                }
+        }
+        return path
+}
+
+// importCandidates compares imported jobs with the installed ones. Jobs that
+// are already installed, or that would share a log file with an installed job,
+// start unchecked.
+func importCandidates(installed, incoming []CronJob) []importCandidate {
+        scheduleKey := func(job CronJob) string {
+                return strings.Join(strings.Fields(job.Expression), " ") + "\x00" + strings.TrimSpace(job.Command)
+        }
+        existing := map[string]CronJob{}
+        logFiles := map[string]CronJob{}
+        for _, job := range installed {
+                existing[scheduleKey(job)] = job
+                if job.LogFile != "" {
+                        logFiles[job.LogFile] = job
+                }
+        }
+
+        candidates := make([]importCandidate, len(incoming))
+        for i, job := range incoming {
+                job.LastRun = GetLastRunFromLogFile(job.LogFile)
+                SetJobDisabled(&job, job.Disabled)
+
+                candidate := importCandidate{Job: job, Selected: true}
+                if other, ok := existing[scheduleKey(job)]; ok {
+                        candidate.Problem = fmt.Sprintf("already installed as %q", jobName(other))
+                } else if other, ok := logFiles[job.LogFile]; ok {
+                        candidate.Problem = fmt.Sprintf("log file %s is used by %q", job.LogFile, jobName(other))
+                } else {
+                        existing[scheduleKey(job)] = job
+                        if job.LogFile != "" {
+                                logFiles[job.LogFile] = job
+                        }
+                }
+                candidate.Selected = candidate.Problem == ""
+                candidates[i] = candidate
+        }
+        return candidates
+}
+
+// mergeImports returns a copy of jobs with the imported jobs added after the
+// crontab jobs, ahead of any timers and system jobs
+func mergeImports(jobs, imported []CronJob) []CronJob {
+        at := 0
+        for i, job := range jobs {
+                if job.Source == SourceCrontab {
+                        at = i + 1
+                }
+        }
+        merged := make([]CronJob, 0, len(jobs)+len(imported))
+        merged = append(merged, jobs[:at]...)
+        merged = append(merged, imported...)
+        return append(merged, jobs[at:]...)
+}
+
+// selectedImports returns the checked jobs
+func selectedImports(candidates []importCandidate) []CronJob {
+        var jobs []CronJob
+        for _, candidate := range candidates {
+                if candidate.Selected {
+                        jobs = append(jobs, candidate.Job)
+                }
+        }
+        return jobs
+}
+
+// openImport switches to the import view, asking for the file first
+func (m Model) openImport() (tea.Model, tea.Cmd) {
+        m.mode = ViewImport
+        m.imports = nil
+        m.importCursor = 0
+        m.importPath.SetValue("")
+        m.importPath.Focus()
+        m.error = ""
+        return m, textinput.Blink
+}
+
+// updateImport handles key presses in the import view
+func (m Model) updateImport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+        var cmd tea.Cmd
+
+        // First the file to import from
+        if m.imports == nil {
+                switch msg.String() {
+                case "ctrl+c", "esc":
+                        m.mode = ViewTable
+                        m.importPath.Blur()
+                        m.error = ""
+                        return m, nil
+
+                case "enter":
+                        jobs, err := readImportFile(strings.TrimSpace(m.importPath.Value()))
+                        if err != nil {
+                                m.error = fmt.Sprintf("Error reading import file: %v", err)
+                                return m, nil
+                        }
+                        if len(jobs) == 0 {
+                                m.error = "No jobs found in that file"
+                                return m, nil
+                        }
+                        // Timers and system jobs in the table aren't in the crontab
+                        var installed []CronJob
+                        for _, job := range m.jobs {
+                                if job.Source == SourceCrontab {
+                                        installed = append(installed, job)
+                                }
+                        }
+                        m.imports = importCandidates(installed, jobs)
+                        m.importPath.Blur()
+                        m.error = ""
+                        return m, nil
+                }
+
+                m.importPath, cmd = m.importPath.Update(msg)
+                return m, cmd
+        }
+
+        // Then which of its jobs to bring in
+        switch msg.String() {
+        case "ctrl+c", "esc", "q":
+                m.mode = ViewTable
+                m.imports = nil
+                return m, nil
+
+        case "up", "k":
+                if m.importCursor > 0 {
+                        m.importCursor--
+                }
+
+        case "down", "j":
+                if m.importCursor < len(m.imports)-1 {
+                        m.importCursor++
+                }
+
+        case " ", "x":
+                m.imports[m.importCursor].Selected = !m.imports[m.importCursor].Selected
+
+        case "a":
+                // Check everything, or clear everything when it all is
+                all := true
+                for _, candidate := range m.imports {
+                        all = all && candidate.Selected
+                }
+                for i := range m.imports {
+                        m.imports[i].Selected = !all
+                }
+
+        case "enter":
+                jobs := selectedImports(m.imports)
+                if len(jobs) == 0 {
+                        m.error = "No jobs are checked"
+                        return m, nil
+                }
+
+                for i := range jobs {
+                        if err := saveCLIJob(&jobs[i]); err != nil {
+                                m.error = err.Error()
+                                return m, nil
+                        }
+                        jobs[i].LastStatus = GetJobStatus(jobs[i])
+                }
+
+                // Merged on a copy, m.jobs only changes once it is saved
+                merged := mergeImports(m.jobs, jobs)
+                if err := WriteCrontab(merged); err != nil {
+                        m.error = fmt.Sprintf("Error saving crontab: %v", err)
+                        return m, nil
+                }
+                m.jobs = merged
+                m.imports = nil
+                m.mode = ViewTable
+                m.updateTable()
+                m.error = ""
+                m.message = fmt.Sprintf("Imported %s", countNoun(len(jobs), "job"))
+        }
+        return m, nil
+}
+
+// viewImport renders the import file prompt, then the jobs to pick from
+func (m Model) viewImport() string {
+        var b strings.Builder
+
+        b.WriteString(titleStyle.Render("Import Jobs"))
+        b.WriteString("\n\n")
+
+        if m.error != "" {
+                b.WriteString(errorStyle.Render("Error: " + m.error))
+                b.WriteString("\n\n")
+        }
+
+        if m.imports == nil {
+                b.WriteString("Crontab file, or a spec from tuicron export:")
+                b.WriteString("\n")
+                b.WriteString(m.importPath.View())
+                b.WriteString("\n")
+                b.WriteString(keybindingStyle.Render("Enter: read file • Esc: cancel"))
+                return b.String()
+        }
+
+        selected := len(selectedImports(m.imports))
+        b.WriteString(helpStyle.Render(fmt.Sprintf("%d of %d jobs checked", selected, len(m.imports))))
+        b.WriteString("\n\n")
+
+        cursorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+        for i, candidate := range m.imports {
+                box := "[ ]"
+                if candidate.Selected {
+                        box = "[x]"
+                }
+                job := candidate.Job
+                line := fmt.Sprintf("%s %-14s %s", box, job.Expression, jobName(job))
+                if i == m.importCursor {
+                        line = cursorStyle.Render(line)
+                }
+                b.WriteString(line)
+                if candidate.Problem != "" {
+                        b.WriteString("  ")
+                        b.WriteString(warningStyle.Render(lintBadge + candidate.Problem))
+                }
+                b.WriteString("\n")
+                b.WriteString(helpStyle.Render("      " + truncateText(job.Command, 80)))
+                b.WriteString("\n")
+        }
+
+        b.WriteString(keybindingStyle.Render(strings.Join([]string{
+                "↑/↓: move",
+                "space: check/uncheck",
+                "a: all/none",
+                "enter: import checked",
+                "esc: cancel",
+        }, " • ")))
+        return b.String()
+}
+
+// runImport implements `tuicron import`
+func runImport(args []string) int {
+        flags := newCLIFlags("import")
+        only := flags.String("select", "", "comma separated numbers of the jobs to import, as listed, instead of every new one")
+        dryRun := flags.Bool("dry-run", false, "only list the jobs in the file")
+        path, err := parseJobArgs(flags, args, true)
+        if err != nil {
+                return cliFail("import", flags, err)
+        }
+
+        incoming, err := readImportFile(path)
+        if err != nil {
+                return cliFail("import", nil, err)
+        }
+        installed, err := loadCLIJobs()
+        if err != nil {
+                return cliFail("import", nil, err)
+        }
+        candidates := importCandidates(installed, incoming)
+
+        // An explicit selection overrides the duplicate checks
+        if *only != "" {
+                for i := range candidates {
+                        candidates[i].Selected = false
+                }
+                for _, field := range strings.Split(*only, ",") {
+                        n, err := strconv.Atoi(strings.TrimSpace(field))
+                        if err != nil || n < 1 || n > len(candidates) {
+                                return cliFail("import", flags, fmt.Errorf("--select: %q is not a job number from 1 to %d", field, len(candidates)))
+                        }
+                        candidates[n-1].Selected = true
+                }
+        }
+
+        for i, candidate := range candidates {
+                mark := " "
+                if candidate.Selected {
+                        mark = "+"
+                }
+                line := fmt.Sprintf("%s %d  %s  %s", mark, i+1, candidate.Job.Expression, jobName(candidate.Job))
+                if candidate.Problem != "" {
+                        line += "  (" + candidate.Problem + ")"
+                }
+                fmt.Println(line)
+        }
+
+        jobs := selectedImports(candidates)
+        if *dryRun {
+                fmt.Printf("Would import %s\n", countNoun(len(jobs), "job"))
+                return 0
+        }
+        if len(jobs) == 0 {
+                fmt.Println("Nothing to import")
+                return 0
+        }
+        for i := range jobs {
+                if err := saveCLIJob(&jobs[i]); err != nil {
+                        return cliFail("import", nil, err)
+                }
+        }
+        if err := WriteCrontab(append(installed, jobs...)); err != nil {
+                return cliFail("import", nil, err)
+        }
+        fmt.Printf("Imported %s\n", countNoun(len(jobs), "job"))
+        return 0
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+        "strings"
+        "testing"
+)
+
+func TestImportCandidates(t *testing.T) {
+        t.Setenv("HOME", t.TempDir())
+        installed := []CronJob{
+                {Description: "Backup", Expression: "0 2 * * *", Command: "/usr/local/bin/backup.sh", LogFile: "backup"},
+                {Description: "Cleanup", Expression: "0 * * * *", Command: "find /tmp -type f -mtime +1 -delete"},
+        }
+        incoming := []CronJob{
+                {Expression: "0  2 * * *", Command: "/usr/local/bin/backup.sh", LogFile: "backup"},
+                {Expression: "30 3 * * *", Command: "/usr/local/bin/report.sh", LogFile: "backup"},
+                {Expression: "*/5 * * * *", Command: "/usr/local/bin/poll.sh", LogFile: "poll"},
+                {Expression: "*/5 * * * *", Command: "/usr/local/bin/poll.sh", LogFile: "poll"},
+                {Expression: "0 4 * * 0", Command: "/usr/local/bin/rotate.sh"},
+        }
+        want := []struct {
+                selected bool
+                problem  string
+        }{
+                {false, `already installed as "Backup"`},
+                {false, `log file backup is used by "Backup"`},
+                {true, ""},
+                {false, `already installed as "poll"`},
+                {true, ""},
+        }
+
+        candidates := importCandidates(installed, incoming)
+        if len(candidates) != len(want) {
+                t.Fatalf("got %d candidates, want %d", len(candidates), len(want))
+        }
+        for i, candidate := range candidates {
+                if candidate.Selected != want[i].selected || candidate.Problem != want[i].problem {
+                        t.Errorf("candidate %d = (%v, %q), want (%v, %q)", i+1, candidate.Selected, candidate.Problem, want[i].selected, want[i].problem)
+                }
+        }
+}
+
+func TestMergeImports(t *testing.T) {
+        jobs := []CronJob{
+                {Command: "a"},
+                {Command: "b"},
+                {Command: "timer", Source: SourceTimer},
+        }
+        merged := mergeImports(jobs, []CronJob{{Command: "c"}})
+
+        var got []string
+        for _, job := range merged {
+                got = append(got, job.Command)
+        }
+        if want := "a b c timer"; strings.Join(got, " ") != want {
+                t.Errorf("mergeImports() = %q, want %q", strings.Join(got, " "), want)
+        }
+        if len(jobs) != 3 || jobs[2].Command != "timer" {
+                t.Errorf("mergeImports() changed the jobs it was given: %v", jobs)
+        }
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+        "github.com/charmbracelet/bubbles/table"
+        "github.com/mattn/go-runewidth"
+)
+
+// columnSpec describes how a table column is sized on different terminal widths
+type columnSpec struct {
+        Title    string
+        MinWidth int
+        MaxWidth int // 0 means no limit
+        Weight   int // Share of the spare width the column receives
+        Priority int // Columns with the highest priority value are hidden first
+}
+
+// jobColumns lists the table columns in display order
+var jobColumns = []columnSpec{
+        {Title: "Description", MinWidth: 12, Weight: 3, Priority: 1},
+        {Title: "Type", MinWidth: 6, MaxWidth: 6, Priority: 7},
+        {Title: "Schedule", MinWidth: 11, MaxWidth: 20, Weight: 1, Priority: 3},
+        {Title: "Next Run", MinWidth: 13, MaxWidth: 13, Priority: 2},
+        {Title: "Last Run", MinWidth: 13, MaxWidth: 13, Priority: 6},
+        {Title: "Command", MinWidth: 16, Weight: 5, Priority: 4},
+        {Title: "Status", MinWidth: 9, MaxWidth: 12, Weight: 1, Priority: 5},
+}
+
+const (
+        cellPadding   = 2   // Left and right padding the table adds to every cell
+        defaultWidth  = 120 // Layout width used until the terminal size is known
+        tableChrome   = 4   // Border around the table plus a little breathing room
+        minTableWidth = 30
+)
+
+// layoutColumns picks which columns fit into width and how wide each one is.
+// It returns the indices into jobColumns that are shown alongside the sized
+// table columns.
+func layoutColumns(width int) ([]int, []table.Column) {
+        available := width - tableChrome
+        if available < minTableWidth {
+                available = minTableWidth
+        }
+
+        // Hide the least important columns until the minimum widths fit
+        shown := make([]bool, len(jobColumns))
+        for i := range shown {
+                shown[i] = true
+        }
+        for {
+                needed := 0
+                hideable := -1
+                for i, spec := range jobColumns {
+                        if !shown[i] {
+                                continue
+                        }
+                        needed += spec.MinWidth + cellPadding
+                        if i > 0 && (hideable == -1 || spec.Priority > jobColumns[hideable].Priority) {
+                                hideable = i
+                        }
+                }
+                if needed <= available || hideable == -1 {
+                        break
+                }
+                shown[hideable] = false
+        }
+
+        // Start every column at its minimum and share the rest out by weight
+        var keys []int
+        widths := map[int]int{}
+        spare := available
+        for i, spec := range jobColumns {
+                if shown[i] {
+                        keys = append(keys, i)
+                        widths[i] = spec.MinWidth
+                        spare -= spec.MinWidth + cellPadding
+                }
+        }
+        for spare > 0 {
+                totalWeight := 0
+                for _, i := range keys {
+                        spec := jobColumns[i]
+                        if spec.MaxWidth == 0 || widths[i] < spec.MaxWidth {
+                                totalWeight += spec.Weight
+                        }
+                }
+                if totalWeight == 0 {
+                        break
+                }
+
+                given := 0
+                for _, i := range keys {
+                        spec := jobColumns[i]
+                        if spec.Weight == 0 || (spec.MaxWidth > 0 && widths[i] >= spec.MaxWidth) {
+                                continue
+                        }
+                        share := spare * spec.Weight / totalWeight
+                        if share == 0 {
+                                share = 1
+                        }
+                        if spec.MaxWidth > 0 && widths[i]+share > spec.MaxWidth {
+                                share = spec.MaxWidth - widths[i]
+                        }
+                        if given+share > spare {
+                                share = spare - given
+                        }
+                        widths[i] += share
+                        given += share
+                }
+                if given == 0 {
+                        break
+                }
+                spare -= given
+        }
+
+        columns := make([]table.Column, len(keys))
+        for n, i := range keys {
+                columns[n] = table.Column{Title: jobColumns[i].Title, Width: widths[i]}
+        }
+        return keys, columns
+}
+
+// truncateText shortens s to fit width terminal cells, ending with an ellipsis
+func truncateText(s string, width int) string {
+        if width <= 0 {
+                return ""
+        }
+        return runewidth.Truncate(s, width, "…")
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+        "fmt"
+        "io"
+        "os"
+        "path/filepath"
+        "regexp"
+        "strings"
+)
+
+// LintSeverity says how likely a lint issue is to stop a job from working
+type LintSeverity int
+
+const (
+        LintWarning LintSeverity = iota
+        LintError
+)
+
+// String returns the severity as shown by `tuicron lint`
+func (s LintSeverity) String() string {
+        if s == LintError {
+                return "error"
+        }
+        return "warning"
+}
+
+// LintIssue is one problem found in a job
+type LintIssue struct {
+        Severity LintSeverity
+        Rule     string // Short name of the check, such as "relative-path"
+        Message  string
+}
+
+// cronDefaultPath is the PATH cron gives jobs when the crontab doesn't set one
+const cronDefaultPath = "/usr/bin:/bin"
+
+// lintBadge marks jobs with lint issues in the table
+const lintBadge = "⚠ "
+
+// shellBuiltins are commands sh runs itself, so they never need PATH
+var shellBuiltins = map[string]bool{
+        ".": true, ":": true, "[": true, "alias": true, "bg": true, "cd": true,
+        "command": true, "echo": true, "eval": true, "exec": true, "exit": true,
+        "export": true, "false": true, "fg": true, "getopts": true, "hash": true,
+        "jobs": true, "kill": true, "printf": true, "pwd": true, "read": true,
+        "readonly": true, "return": true, "set": true, "shift": true, "test": true,
+        "times": true, "trap": true, "true": true, "type": true, "ulimit": true,
+        "umask": true, "unalias": true, "unset": true, "wait": true,
+}
+
+// scriptInterpreters run the script named by their first argument
+var scriptInterpreters = map[string]bool{
+        "sh": true, "bash": true, "dash": true, "zsh": true, "ksh": true,
+        "python": true, "python3": true, "perl": true, "ruby": true, "node": true, "php": true,
+}
+
+// assignmentRegex matches a NAME=value word in front of a command
+var assignmentRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// shellOperators end the first simple command of a command line
+var shellOperators = map[string]bool{
+        "&&": true, "||": true, ";": true, "|": true, "&": true,
+}
+
+// LintJobs checks every job for common crontab mistakes. The result holds the
+// issues for each job, in the same order as jobs.
+func LintJobs(jobs []CronJob) [][]LintIssue {
+        issues := make([][]LintIssue, len(jobs))
+
+        // Jobs with the same schedule and command, keyed by both
+        seen := map[string]int{}
+        for i, job := range jobs {
+                // The checks are about how cron runs commands
+                if job.Source != SourceCrontab {
+                        continue
+                }
+                issues[i] = lintJob(job)
+                if job.Disabled {
+                        continue
+                }
+
+                key := strings.Join(strings.Fields(job.Expression), " ") + "\x00" + strings.TrimSpace(job.Command)
+                if first, ok := seen[key]; ok {
+                        issues[i] = append(issues[i], LintIssue{LintWarning, "duplicate",
+                                fmt.Sprintf("Same schedule and command as %q, so the command runs twice", jobName(jobs[first]))})
+                        continue
+                }
+                seen[key] = i
+        }
+
+        return issues
+}
+
+// lintJob runs the checks that only need the job itself
+func lintJob(job CronJob) []LintIssue {
+        var issues []LintIssue
+        add := func(severity LintSeverity, rule, format string, args ...interface{}) {
+                issues = append(issues, LintIssue{severity, rule, fmt.Sprintf(format, args...)})
+        }
+
+        // A minute field of * fires every minute of every hour that matches
+        if fields := strings.Fields(job.Expression); len(fields) == 5 && (fields[0] == "*" || fields[0] == "*/1") {
+                if fields[1] == "*" || fields[1] == "*/1" {
+                        add(LintWarning, "every-minute", "Runs every minute")
+                } else {
+                        add(LintWarning, "every-minute", "Runs every minute of the matching hours, use 0 as the minute to run once an hour")
+                }
+        }
+
+        if job.Stdin != "" {
+                add(LintWarning, "unescaped-percent", "The crontab line has a bare %%, so cron cut the command there and sends %q as its input. Write \\%% for a literal %%", job.Stdin)
+        }
+
+        for _, secret := range FindSecrets(job.Command) {
+                add(LintWarning, "secret", "Contains what looks like %s, edit the job to move it to an env file", secret.Kind)
+        }
+
+        path, pathSet := cronDefaultPath, false
+        for _, assignment := range job.Env {
+                if strings.HasPrefix(assignment, "PATH=") {
+                        path, pathSet = strings.TrimPrefix(assignment, "PATH="), true
+                }
+                if strings.Contains(assignment, "~") {
+                        add(LintWarning, "tilde", "%s uses ~, which cron doesn't expand in variables, use the full path", assignment)
+                }
+        }
+
+        words, err := splitShellWords(job.Command)
+        if err != nil {
+                return issues
+        }
+
+        for _, word := range words {
+                if word == "sudo" {
+                        add(LintWarning, "sudo", "Uses sudo, which can't ask for a password under cron, put the job in root's crontab instead")
+                        break
+                }
+        }
+        for _, word := range words {
+                if strings.HasPrefix(word, "~") || strings.Contains(word, "=~/") || strings.Contains(word, ":~/") {
+                        add(LintWarning, "tilde", "Uses ~ in %s, which isn't expanded inside quotes or variables, use $HOME or the full path", word)
+                        break
+                }
+        }
+
+        // Only the first simple command is checked, after any NAME=value prefixes
+        var command []string
+        for _, word := range words {
+                if shellOperators[word] {
+                        break
+                }
+                if len(command) == 0 && assignmentRegex.MatchString(word) {
+                        continue
+                }
+                command = append(command, strings.TrimSuffix(word, ";"))
+        }
+        if len(command) == 0 {
+                return issues
+        }
+
+        program := command[0]
+        if !strings.Contains(program, "/") {
+                if !shellBuiltins[program] && !strings.ContainsAny(program, "$`") && findInPath(program, path) == "" {
+                        if pathSet {
+                                add(LintError, "missing-path", "%s isn't in the job's PATH", program)
+                        } else {
+                                add(LintWarning, "missing-path", "%s isn't in cron's default PATH (%s) and the crontab doesn't set PATH", program, cronDefaultPath)
+                        }
+                }
+                if program = filepath.Base(program); scriptInterpreters[program] {
+                        for _, arg := range command[1:] {
+                                // sh -c and python -m take code or a module, not a script
+                                if arg == "-c" || arg == "-m" {
+                                        break
+                                }
+                                if !strings.HasPrefix(arg, "-") {
+                                        issues = append(issues, lintPath(arg, false)...)
+                                        break
+                                }
+                        }
+                }
+                return issues
+        }
+
+        return append(issues, lintPath(program, true)...)
+}
+
+// lintPath checks a script path from a job's command. Paths that depend on the
+// shell, such as ~ or variables, are left alone.
+func lintPath(path string, executable bool) []LintIssue {
+        if strings.HasPrefix(path, "~") || strings.ContainsAny(path, "$`*?") {
+                return nil
+        }
+        if !filepath.IsAbs(path) {
+                return []LintIssue{{LintWarning, "relative-path",
+                        fmt.Sprintf("%s is a relative path, but cron starts jobs in your home directory, use the full path", path)}}
+        }
+
+        info, err := os.Stat(path)
+        if err != nil {
+                return []LintIssue{{LintError, "missing-script", fmt.Sprintf("%s doesn't exist", path)}}
+        }
+        if executable && !info.IsDir() && info.Mode()&0111 == 0 {
+                return []LintIssue{{LintError, "not-executable", fmt.Sprintf("%s isn't executable, run chmod +x on it", path)}}
+        }
+        return nil
+}
+
+// findInPath looks a program up in a colon separated PATH, returning "" when
+// it isn't there
+func findInPath(program, path string) string {
+        for _, dir := range filepath.SplitList(path) {
+                if dir == "" || strings.ContainsAny(dir, "$~") {
+                        continue
+                }
+                candidate := filepath.Join(dir, program)
+                if info, err := os.Stat(candidate); err == nil && !info.IsDir() && info.Mode()&0111 != 0 {
+                        return candidate
+                }
+        }
+        return ""
+}
+
+// lintSummary counts the issues, for the line under the table
+func lintSummary(issues [][]LintIssue) (int, int) {
+        total, jobs := 0, 0
+        for _, jobIssues := range issues {
+                if len(jobIssues) > 0 {
+                        total += len(jobIssues)
+                        jobs++
+                }
+        }
+        return total, jobs
+}
+
+// runLint implements `tuicron lint`, which checks the user's crontab or the
+// crontab file given as an argument. It exits with 1 when there are issues.
+func runLint(args []string) int {
+        var content string
+        switch {
+        case len(args) > 1:
+                fmt.Fprintln(os.Stderr, "usage: tuicron lint [crontab file, or - for stdin]")
+                return 2
+        case len(args) == 1 && args[0] == "-":
+                data, err := io.ReadAll(os.Stdin)
+                if err != nil {
+                        fmt.Fprintf(os.Stderr, "tuicron lint: %v\n", err)
+                        return 2
+                }
+                content = string(data)
+        case len(args) == 1:
+                data, err := os.ReadFile(args[0])
+                if err != nil {
+                        fmt.Fprintf(os.Stderr, "tuicron lint: %v\n", err)
+                        return 2
+                }
+                content = string(data)
+        default:
+                text, err := ReadCrontabText()
+                if err != nil {
+                        fmt.Fprintf(os.Stderr, "tuicron lint: %v\n", err)
+                        return 2
+                }
+                content = text
+        }
+
+        jobs, err := ParseCrontab(content)
+        if err != nil {
+                fmt.Fprintf(os.Stderr, "tuicron lint: %v\n", err)
+                return 2
+        }
+
+        issues := LintJobs(jobs)
+        for i, jobIssues := range issues {
+                if len(jobIssues) == 0 {
+                        continue
+                }
+                fmt.Printf("%s  %s\n", jobs[i].Expression, jobName(jobs[i]))
+                for _, issue := range jobIssues {
+                        fmt.Printf("  %s: %s [%s]\n", issue.Severity, issue.Message, issue.Rule)
+                }
+        }
+
+        total, affected := lintSummary(issues)
+        if total == 0 {
+                fmt.Printf("No issues in %s\n", countNoun(len(jobs), "job"))
+                return 0
+        }
+        fmt.Printf("%s in %d of %s\n", countNoun(total, "issue"), affected, countNoun(len(jobs), "job"))
+        return 1
+}
+
+// countNoun formats a count with a noun, adding an s unless there is one
+func countNoun(n int, noun string) string {
+        if n == 1 {
+                return "1 " + noun
+        }
+        return fmt.Sprintf("%d %ss", n, noun)
+}
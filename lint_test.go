@@ -0,0 +1,77 @@
+package main
+
+import (
+        "os"
+        "path/filepath"
+        "strings"
+        "testing"
+)
+
+// lintRules lists the rules of the issues, for comparing in tests
+func lintRules(issues []LintIssue) string {
+        var rules []string
+        for _, issue := range issues {
+                rules = append(rules, issue.Rule)
+        }
+        return strings.Join(rules, " ")
+}
+
+func TestLintJob(t *testing.T) {
+        dir := t.TempDir()
+        script := filepath.Join(dir, "run.sh")
+        plain := filepath.Join(dir, "plain.sh")
+        if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0755); err != nil {
+                t.Fatal(err)
+        }
+        if err := os.WriteFile(plain, []byte("#!/bin/sh\n"), 0644); err != nil {
+                t.Fatal(err)
+        }
+        if err := os.WriteFile(filepath.Join(dir, "sudo"), []byte("#!/bin/sh\n"), 0755); err != nil {
+                t.Fatal(err)
+        }
+
+        tests := []struct {
+                job  CronJob
+                want string
+        }{
+                {CronJob{Expression: "0 2 * * *", Command: script}, ""},
+                {CronJob{Expression: "* * * * *", Command: script}, "every-minute"},
+                {CronJob{Expression: "* 9-17 * * *", Command: script}, "every-minute"},
+                {CronJob{Expression: "0 2 * * *", Command: plain}, "not-executable"},
+                {CronJob{Expression: "0 2 * * *", Command: filepath.Join(dir, "missing.sh")}, "missing-script"},
+                {CronJob{Expression: "0 2 * * *", Command: "scripts/backup.sh --full"}, "relative-path"},
+                {CronJob{Expression: "0 2 * * *", Command: "sh backup.sh"}, "relative-path"},
+                {CronJob{Expression: "0 2 * * *", Command: "sh -c 'backup.sh'"}, ""},
+                {CronJob{Expression: "0 2 * * *", Command: "cd /tmp && ./backup.sh"}, ""},
+                {CronJob{Expression: "0 2 * * *", Command: "LANG=C " + script + " > /dev/null"}, ""},
+                {CronJob{Expression: "0 2 * * *", Command: "sudo run.sh", Env: []string{"PATH=" + dir}}, "sudo"},
+                {CronJob{Expression: "0 2 * * *", Command: "tuicron-no-such-program --run"}, "missing-path"},
+                {CronJob{Expression: "0 2 * * *", Command: "run.sh", Env: []string{"PATH=" + dir}}, ""},
+                {CronJob{Expression: "0 2 * * *", Command: "backup", Env: []string{"PATH=" + dir}}, "missing-path"},
+                {CronJob{Expression: "0 2 * * *", Command: script + " ~/backups"}, "tilde"},
+                {CronJob{Expression: "0 2 * * *", Command: script, Env: []string{"BACKUPS=~/backups"}}, "tilde"},
+                {CronJob{Expression: "0 2 * * *", Command: "date +", Stdin: "F"}, "unescaped-percent"},
+                {CronJob{Expression: "0 2 * * *", Command: script + " --password=S3cretPass1"}, "secret"},
+        }
+        for _, tt := range tests {
+                if got := lintRules(lintJob(tt.job)); got != tt.want {
+                        t.Errorf("lintJob(%q) = %q, want %q", tt.job.Command, got, tt.want)
+                }
+        }
+}
+
+func TestLintJobsDuplicates(t *testing.T) {
+        jobs := []CronJob{
+                {Expression: "0 2 * * *", Command: "/usr/local/bin/backup"},
+                {Expression: "0  2 * * *", Command: "/usr/local/bin/backup "},
+                {Expression: "0 2 * * *", Command: "/usr/local/bin/backup", Disabled: true},
+                {Expression: "0 2 * * *", Command: "/usr/local/bin/backup", Source: SourceTimer},
+        }
+        issues := LintJobs(jobs)
+        want := []string{"missing-script", "missing-script duplicate", "missing-script", ""}
+        for i := range jobs {
+                if got := lintRules(issues[i]); got != want[i] {
+                        t.Errorf("job %d issues = %q, want %q", i+1, got, want[i])
+                }
+        }
+}
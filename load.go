@@ -0,0 +1,439 @@
+package main
+
+import (
+        "fmt"
+        "sort"
+        "strconv"
+        "strings"
+        "time"
+
+        "github.com/charmbracelet/bubbletea"
+)
+
+const (
+        // hotspotMinJobs is how many jobs must start in the same minute to
+        // count as a hotspot
+        hotspotMinJobs = 3
+
+        // durationSampleRuns is how many recent runs are considered when
+        // estimating how long a job takes
+        durationSampleRuns = 10
+
+        // loadListLimit caps each list below the heatmap
+        loadListLimit = 5
+)
+
+// heatShades are drawn from quiet to busy
+var heatShades = []string{"░", "▒", "▓", "█"}
+
+// hotspot is a set of jobs that start in the same minute
+type hotspot struct {
+        Jobs  []int
+        Times []time.Time
+}
+
+// staggerSuggestion proposes a new expression for a job caught in a hotspot
+type staggerSuggestion struct {
+        Job        int
+        Expression string
+}
+
+// jobOverlap records a job that is likely still running when another starts
+type jobOverlap struct {
+        Job      int
+        Other    int // Same as Job when a run outlasts the gap to its next run
+        Duration time.Duration
+        Count    int
+        First    time.Time
+}
+
+// loadReport summarises how busy the schedule is over a window
+type loadReport struct {
+        Week        bool // 7 days of hourly buckets instead of 24 hours of minutes
+        Start       time.Time
+        Counts      []int // Runs per bucket
+        Busiest     int
+        Total       int
+        Hotspots    []hotspot
+        Suggestions []staggerSuggestion
+        Overlaps    []jobOverlap
+}
+
+// bucket returns the length of one heatmap cell
+func (r loadReport) bucket() time.Duration {
+        if r.Week {
+                return time.Hour
+        }
+        return time.Minute
+}
+
+// longestRecentRun returns the longest of a job's recent run durations
+func longestRecentRun(job CronJob) time.Duration {
+        durations := GetRunDurationsFromLogFile(job.LogFile)
+        if len(durations) > durationSampleRuns {
+                durations = durations[len(durations)-durationSampleRuns:]
+        }
+        var longest time.Duration
+        for _, d := range durations {
+                if d > longest {
+                        longest = d
+                }
+        }
+        return longest
+}
+
+// analyzeLoad buckets the runs of the given jobs over the next 24 hours, or
+// the next 7 days when week is set, and looks for hotspots and overlaps
+func analyzeLoad(jobs []CronJob, indices []int, now time.Time, week bool) loadReport {
+        report := loadReport{Week: week}
+        var end time.Time
+        if week {
+                report.Start = startOfDay(now)
+                end = report.Start.AddDate(0, 0, 7)
+                report.Counts = make([]int, 7*24)
+        } else {
+                report.Start = now.Truncate(time.Minute).Add(-time.Duration(now.Minute()) * time.Minute)
+                end = report.Start.Add(24 * time.Hour)
+                report.Counts = make([]int, 24*60)
+        }
+
+        runs := runsBetween(jobs, indices, report.Start, end)
+        report.Total = len(runs)
+
+        byMinute := map[int64][]int{}
+        var minutes []int64
+        for _, run := range runs {
+                slot := int(run.Time.Sub(report.Start) / report.bucket())
+                if slot >= 0 && slot < len(report.Counts) {
+                        report.Counts[slot]++
+                        if report.Counts[slot] > report.Busiest {
+                                report.Busiest = report.Counts[slot]
+                        }
+                }
+
+                minute := run.Time.Unix() / 60
+                if byMinute[minute] == nil {
+                        minutes = append(minutes, minute)
+                }
+                byMinute[minute] = append(byMinute[minute], run.Job)
+        }
+
+        // Hotspots, grouped by the set of jobs involved so that an hourly
+        // collision shows up once rather than 24 times
+        groups := map[string]int{}
+        for _, minute := range minutes {
+                jobsAt := byMinute[minute]
+                if len(jobsAt) < hotspotMinJobs {
+                        continue
+                }
+                sort.Ints(jobsAt)
+                key := fmt.Sprint(jobsAt)
+                i, ok := groups[key]
+                if !ok {
+                        i = len(report.Hotspots)
+                        groups[key] = i
+                        report.Hotspots = append(report.Hotspots, hotspot{Jobs: jobsAt})
+                }
+                report.Hotspots[i].Times = append(report.Hotspots[i].Times, time.Unix(minute*60, 0).In(now.Location()))
+        }
+        sort.SliceStable(report.Hotspots, func(a, b int) bool {
+                return len(report.Hotspots[a].Jobs)*len(report.Hotspots[a].Times) >
+                        len(report.Hotspots[b].Jobs)*len(report.Hotspots[b].Times)
+        })
+
+        report.Suggestions = suggestStaggers(jobs, runs, report.Hotspots)
+        report.Overlaps = findOverlaps(jobs, indices, runs)
+
+        return report
+}
+
+// suggestStaggers moves all but the first job of each hotspot to the least
+// busy minute of the hour. Only jobs that run at a single fixed minute are
+// moved, since shifting a stepped minute field changes how often it runs.
+func suggestStaggers(jobs []CronJob, runs []jobRun, hotspots []hotspot) []staggerSuggestion {
+        var minuteLoad [60]int
+        perJob := map[int]int{}
+        for _, run := range runs {
+                minuteLoad[run.Time.Minute()]++
+                perJob[run.Job]++
+        }
+
+        var suggestions []staggerSuggestion
+        moved := map[int]bool{}
+        for _, spot := range hotspots {
+                for _, job := range spot.Jobs[1:] {
+                        // Only the user's own crontab can be changed
+                        if moved[job] || jobs[job].Source != SourceCrontab {
+                                continue
+                        }
+                        ast, err := parseCronAST(jobs[job].Expression)
+                        if err != nil {
+                                continue
+                        }
+                        values, ok := ast.Minute.Singles()
+                        if !ok || len(values) != 1 {
+                                continue
+                        }
+
+                        current := values[0]
+                        best := -1
+                        for minute := 0; minute < 60; minute++ {
+                                if minute == current {
+                                        continue
+                                }
+                                if best < 0 || minuteLoad[minute] < minuteLoad[best] ||
+                                        (minuteLoad[minute] == minuteLoad[best] && minuteDistance(minute, current) < minuteDistance(best, current)) {
+                                        best = minute
+                                }
+                        }
+                        minuteLoad[current] -= perJob[job]
+                        minuteLoad[best] += perJob[job]
+                        moved[job] = true
+
+                        suggestions = append(suggestions, staggerSuggestion{
+                                Job:        job,
+                                Expression: withMinute(jobs[job].Expression, best),
+                        })
+                }
+        }
+        return suggestions
+}
+
+// minuteDistance is the distance between two minutes on the clock face
+func minuteDistance(a, b int) int {
+        d := a - b
+        if d < 0 {
+                d = -d
+        }
+        if d > 30 {
+                d = 60 - d
+        }
+        return d
+}
+
+// withMinute replaces the minute field of an expression, expanding @ shorthands
+func withMinute(expr string, minute int) string {
+        expr = strings.TrimSpace(expr)
+        if expanded, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+                expr = expanded
+        }
+        fields := strings.Fields(expr)
+        if len(fields) != 5 {
+                return expr
+        }
+        fields[0] = strconv.Itoa(minute)
+        return strings.Join(fields, " ")
+}
+
+// findOverlaps warns about runs that are likely to still be going when another
+// job, or the next run of the same job, starts. Durations come from the logs.
+func findOverlaps(jobs []CronJob, indices []int, runs []jobRun) []jobOverlap {
+        durations := map[int]time.Duration{}
+        for _, i := range indices {
+                if d := longestRecentRun(jobs[i]); d >= time.Minute {
+                        durations[i] = d
+                }
+        }
+        if len(durations) == 0 {
+                return nil
+        }
+
+        var overlaps []jobOverlap
+        found := map[[2]int]int{}
+        for i, run := range runs {
+                d, ok := durations[run.Job]
+                if !ok {
+                        continue
+                }
+                finish := run.Time.Add(d)
+                for _, other := range runs[i+1:] {
+                        if !other.Time.Before(finish) {
+                                break
+                        }
+                        key := [2]int{run.Job, other.Job}
+                        n, ok := found[key]
+                        if !ok {
+                                n = len(overlaps)
+                                found[key] = n
+                                overlaps = append(overlaps, jobOverlap{Job: run.Job, Other: other.Job, Duration: d, First: other.Time})
+                        }
+                        overlaps[n].Count++
+                }
+        }
+        return overlaps
+}
+
+// openLoad switches to the load view, starting with the next 24 hours
+func (m Model) openLoad() (tea.Model, tea.Cmd) {
+        m.mode = ViewLoad
+        m.load = analyzeLoad(m.jobs, m.visible, time.Now(), false)
+        return m, nil
+}
+
+// updateLoad handles key presses in the load view
+func (m Model) updateLoad(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+        switch msg.String() {
+        case "esc", "q":
+                m.mode = ViewTable
+        case "w", "tab":
+                m.load = analyzeLoad(m.jobs, m.visible, time.Now(), !m.load.Week)
+        case "r":
+                m.load = analyzeLoad(m.jobs, m.visible, time.Now(), m.load.Week)
+        }
+        return m, nil
+}
+
+// viewLoad renders the heatmap followed by hotspots, suggestions and overlaps
+func (m Model) viewLoad() string {
+        var b strings.Builder
+        report := m.load
+
+        if report.Week {
+                b.WriteString(titleStyle.Render(fmt.Sprintf("Load: next 7 days (%d runs)", report.Total)))
+        } else {
+                b.WriteString(titleStyle.Render(fmt.Sprintf("Load: next 24 hours (%d runs)", report.Total)))
+        }
+        b.WriteString("\n")
+        b.WriteString(m.viewHeatmap())
+        b.WriteString("\n")
+
+        // Hotspots
+        b.WriteString(helpStyle.Render(fmt.Sprintf("Hotspots (%d+ jobs in the same minute):", hotspotMinJobs)))
+        b.WriteString("\n")
+        if len(report.Hotspots) == 0 {
+                b.WriteString(successStyle.Render("  None"))
+                b.WriteString("\n")
+        }
+        for i, spot := range report.Hotspots {
+                if i == loadListLimit {
+                        b.WriteString(helpStyle.Render(fmt.Sprintf("  … and %d more", len(report.Hotspots)-i)))
+                        b.WriteString("\n")
+                        break
+                }
+                var names []string
+                for _, job := range spot.Jobs {
+                        names = append(names, jobName(m.jobs[job]))
+                }
+                line := fmt.Sprintf("  %d jobs at %s", len(spot.Jobs), spot.Times[0].Format("Mon 15:04"))
+                if len(spot.Times) > 1 {
+                        line += fmt.Sprintf(" and %s more", countTimes(len(spot.Times)-1))
+                }
+                b.WriteString(errorStyle.Render(line))
+                b.WriteString(helpStyle.Render(": " + truncateText(strings.Join(names, ", "), m.width-len(line)-10)))
+                b.WriteString("\n")
+        }
+
+        // Suggestions
+        if len(report.Suggestions) > 0 {
+                b.WriteString("\n")
+                b.WriteString(helpStyle.Render("Suggested staggered schedules:"))
+                b.WriteString("\n")
+                for i, suggestion := range report.Suggestions {
+                        if i == loadListLimit {
+                                b.WriteString(helpStyle.Render(fmt.Sprintf("  … and %d more", len(report.Suggestions)-i)))
+                                b.WriteString("\n")
+                                break
+                        }
+                        job := m.jobs[suggestion.Job]
+                        b.WriteString(fmt.Sprintf("  %s: %s → %s\n",
+                                jobName(job), job.Expression, successStyle.Render(suggestion.Expression)))
+                }
+        }
+
+        // Overlaps
+        b.WriteString("\n")
+        b.WriteString(helpStyle.Render("Likely overlaps (from run durations in the logs):"))
+        b.WriteString("\n")
+        if len(report.Overlaps) == 0 {
+                b.WriteString(successStyle.Render("  None"))
+                b.WriteString("\n")
+        }
+        for i, overlap := range report.Overlaps {
+                if i == loadListLimit {
+                        b.WriteString(helpStyle.Render(fmt.Sprintf("  … and %d more", len(report.Overlaps)-i)))
+                        b.WriteString("\n")
+                        break
+                }
+                var line string
+                if overlap.Job == overlap.Other {
+                        line = fmt.Sprintf("  ⚠ %s takes up to %s and may still be running when its next run starts (%s, first %s)",
+                                jobName(m.jobs[overlap.Job]), overlap.Duration, countTimes(overlap.Count), overlap.First.Format("Mon 15:04"))
+                } else {
+                        line = fmt.Sprintf("  ⚠ %s takes up to %s and may still be running when %s starts (%s, first %s)",
+                                jobName(m.jobs[overlap.Job]), overlap.Duration, jobName(m.jobs[overlap.Other]), countTimes(overlap.Count), overlap.First.Format("Mon 15:04"))
+                }
+                b.WriteString(cronDescStyle.Render(truncateText(line, m.width-6)))
+                b.WriteString("\n")
+        }
+        b.WriteString("\n")
+
+        // Keybindings
+        keybindings := []string{
+                "w/tab: 24 hours/7 days",
+                "r: refresh",
+                "Esc/q: back to jobs",
+        }
+        b.WriteString(keybindingStyle.Render(strings.Join(keybindings, " • ")))
+
+        return baseStyle.Render(b.String())
+}
+
+// viewHeatmap draws one row per hour (24 hour window) or per day (7 day
+// window), with a cell per minute or per hour
+func (m Model) viewHeatmap() string {
+        var b strings.Builder
+        report := m.load
+
+        rows, cols, cellWidth := 24, 60, 1
+        if report.Week {
+                rows, cols, cellWidth = 7, 24, 2
+        }
+
+        // Column ruler
+        b.WriteString(strings.Repeat(" ", 11))
+        for col := 0; col < cols; col += 10 / cellWidth {
+                b.WriteString(helpStyle.Render(fmt.Sprintf("%-10s", fmt.Sprintf("%02d", col))))
+        }
+        b.WriteString("\n")
+
+        for row := 0; row < rows; row++ {
+                var label string
+                if report.Week {
+                        label = report.Start.AddDate(0, 0, row).Format("Mon Jan 2")
+                } else {
+                        label = report.Start.Add(time.Duration(row) * time.Hour).Format("Mon 15h")
+                }
+                b.WriteString(fmt.Sprintf("%-11s", label))
+
+                total := 0
+                for col := 0; col < cols; col++ {
+                        count := report.Counts[row*cols+col]
+                        total += count
+                        b.WriteString(heatCell(count, report.Busiest, cellWidth))
+                }
+                b.WriteString(helpStyle.Render(fmt.Sprintf(" %4d", total)))
+                b.WriteString("\n")
+        }
+
+        return b.String()
+}
+
+// heatCell shades one heatmap cell by its count relative to the busiest cell
+func heatCell(count, busiest, width int) string {
+        if count == 0 {
+                return calendarQuietStyle.Render(strings.Repeat("·", width))
+        }
+        shade := count*len(heatShades)/busiest - 1
+        if shade < 0 {
+                shade = 0
+        }
+        return loadStyle(count, busiest).Render(strings.Repeat(heatShades[shade], width))
+}
+
+// countTimes spells out how many times something happens
+func countTimes(n int) string {
+        if n == 1 {
+                return "once"
+        }
+        return fmt.Sprintf("%d times", n)
+}
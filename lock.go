@@ -0,0 +1,71 @@
+package main
+
+import (
+        "fmt"
+        "hash/fnv"
+        "os"
+        "path/filepath"
+        "syscall"
+)
+
+// StatusRunning is shown while a job holds its overlap lock
+const StatusRunning = "Running"
+
+// JobID returns a stable name for a job, used for its lock file. Jobs with a
+// log file are named after it, others after a hash of their command.
+func JobID(job CronJob) string {
+        if job.LogFile != "" {
+                return job.LogFile
+        }
+        h := fnv.New32a()
+        h.Write([]byte(job.Command))
+        return fmt.Sprintf("job-%08x", h.Sum32())
+}
+
+// GetLockDir returns the directory holding the per-job lock files
+func GetLockDir() string {
+        homeDir, _ := os.UserHomeDir()
+        return filepath.Join(homeDir, ".cron_history", "locks")
+}
+
+// CreateLockDir creates the lock directory if it doesn't exist
+func CreateLockDir() error {
+        return os.MkdirAll(GetLockDir(), 0755)
+}
+
+// GetLockFilePath returns the full path to a job's lock file
+func GetLockFilePath(job CronJob) string {
+        return filepath.Join(GetLockDir(), JobID(job)+".lock")
+}
+
+// ExtractLockFileFromCommand returns the lock file a wrapped command uses
+func ExtractLockFileFromCommand(command string) string {
+        return parseWrappers(command).LockFile
+}
+
+// IsLockHeld reports whether another process currently holds the lock file
+func IsLockHeld(lockPath string) bool {
+        file, err := os.Open(lockPath)
+        if err != nil {
+                return false
+        }
+        defer file.Close()
+
+        if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+                return err == syscall.EWOULDBLOCK
+        }
+        syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+        return false
+}
+
+// GetJobStatus returns Running while a job holds its lock, Disabled for jobs
+// cron skips, otherwise the outcome of its last run
+func GetJobStatus(job CronJob) string {
+        if job.NoOverlap && IsLockHeld(GetLockFilePath(job)) {
+                return StatusRunning
+        }
+        if job.Disabled {
+                return StatusDisabled
+        }
+        return GetLastStatusFromLogFile(job.LogFile)
+}
@@ -109,6 +109,38 @@ func getSystemdJournalEntries(command string) []LogEntry {
         return parseJournalEntries(string(output))
 }
 
+// GetTimerHistory retrieves the journal entries of a systemd timer's service,
+// most recent first
+func GetTimerHistory(unit string) []LogEntry {
+        cmd := exec.Command("journalctl", "--user", "-u", unit, "--since", "1 month ago", "-n", "100", "--output", "short-iso", "--no-pager")
+        output, err := cmd.Output()
+        if err != nil {
+                return nil
+        }
+
+        entries := parseJournalEntries(string(output))
+        for i, entry := range entries {
+                message := strings.ToLower(entry.Message)
+                switch {
+                case strings.Contains(message, "started "):
+                        entries[i].Status = "started"
+                case strings.Contains(message, "finished ") || strings.Contains(message, "deactivated successfully") || strings.Contains(message, "succeeded"):
+                        entries[i].Status = "finished"
+                case strings.Contains(message, "fail"):
+                        entries[i].Status = "failed"
+                default:
+                        entries[i].Status = "output"
+                }
+                // Shown as log lines are, with their time in front
+                entries[i].Message = entry.Timestamp.Format("2006-01-02 15:04:05") + " - " + entry.Message
+        }
+
+        for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+                entries[i], entries[j] = entries[j], entries[i]
+        }
+        return entries
+}
+
 // checkSyslog checks /var/log/syslog for cron entries
 func checkSyslog(command string) time.Time {
         file, err := os.Open("/var/log/syslog")
@@ -274,11 +306,13 @@ func parseJournalEntries(output string) []LogEntry {
         var entries []LogEntry
         lines := strings.Split(strings.TrimSpace(output), "\n")
 
-        timestampRegex := regexp.MustCompile(`(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}[\+\-]\d{4})\s+\S+\s+(.*)`)
+        // Newer journalctl versions put a colon in the zone offset
+        timestampRegex := regexp.MustCompile(`(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}[\+\-]\d{2}:?\d{2})\s+\S+\s+(.*)`)
 
         for _, line := range lines {
                 if matches := timestampRegex.FindStringSubmatch(line); matches != nil {
-                        if t, err := time.Parse("2006-01-02T15:04:05-0700", matches[1]); err == nil {
+                        stamp := matches[1][:len(matches[1])-3] + strings.TrimPrefix(matches[1][len(matches[1])-3:], ":")
+                        if t, err := time.Parse("2006-01-02T15:04:05-0700", stamp); err == nil {
                                 entry := LogEntry{
                                         Timestamp: t,
                                         Status:    "executed",
@@ -1,12 +1,28 @@
 package main
 
 import (
+        "fmt"
         "log"
+        "os"
 
         tea "github.com/charmbracelet/bubbletea"
 )
 
 func main() {
+        // cron runs jobs through `tuicron exec`, the other subcommands are for
+        // scripts and provisioning tools that have no terminal
+        if len(os.Args) > 1 {
+                command, ok := findCLICommand(os.Args[1])
+                if !ok && (os.Args[1] == "-h" || os.Args[1] == "--help") {
+                        command, ok = findCLICommand("help")
+                }
+                if !ok {
+                        fmt.Fprintf(os.Stderr, "tuicron: unknown command %q, see tuicron help\n", os.Args[1])
+                        os.Exit(2)
+                }
+                os.Exit(command.Run(os.Args[2:]))
+        }
+
         m := NewModel()
         p := tea.NewProgram(m, tea.WithAltScreen())
 
@@ -0,0 +1,361 @@
+package main
+
+import (
+        "fmt"
+        "regexp"
+        "strconv"
+        "strings"
+)
+
+// NaturalSchedule is the result of converting an English phrase to cron
+type NaturalSchedule struct {
+        Expression string
+        Note       string // Caveat about how the expression behaves, if any
+}
+
+// NotExpressibleError explains why a phrase can't be written in standard cron
+type NotExpressibleError struct {
+        Reason string
+}
+
+func (e *NotExpressibleError) Error() string {
+        return "can't be expressed in standard cron: " + e.Reason
+}
+
+var (
+        naturalTimePattern = `(?:\d{1,2}(?::\d{2})?\s*(?:am|pm)?|noon|midnight)`
+        naturalDayPattern  = `(?:monday|tuesday|wednesday|thursday|friday|saturday|sunday|mon|tues|tue|wed|thurs|thur|thu|fri|sat|sun)s?`
+
+        nthWeekdayRegex   = regexp.MustCompile(`\b(first|second|third|fourth|fifth|last|1st|2nd|3rd|4th|5th)\s+(` + naturalDayPattern + `)\b`)
+        lastDayRegex      = regexp.MustCompile(`\blast\s+day\b`)
+        secondsRegex      = regexp.MustCompile(`\bevery\s+(\d+\s+)?seconds?\b`)
+        otherWeekRegex    = regexp.MustCompile(`\b(every\s+other\s+week|biweekly|fortnightly|every\s+\d+\s+weeks)\b`)
+        everyMinutesRegex = regexp.MustCompile(`\bevery\s+(\d+)\s+minutes?\b`)
+        everyMinuteRegex  = regexp.MustCompile(`\bevery\s+minute\b`)
+        everyHoursRegex   = regexp.MustCompile(`\bevery\s+(\d+)\s+hours?\b`)
+        everyHourRegex    = regexp.MustCompile(`\b(every\s+hour|hourly)\b`)
+        businessRegex     = regexp.MustCompile(`\b(during|in)\s+(business|working|office)\s+hours\b`)
+        betweenRegex      = regexp.MustCompile(`\b(?:between|from)\s+(` + naturalTimePattern + `)\s+(?:and|to|until)\s+(` + naturalTimePattern + `)`)
+        atTimesRegex      = regexp.MustCompile(`\bat\s+(` + naturalTimePattern + `(?:\s*(?:,|and)\s*` + naturalTimePattern + `)*)`)
+        timeRegex         = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+        weekdaysRegex     = regexp.MustCompile(`\b(every\s+)?(weekdays?|work\s*days?)\b`)
+        weekendsRegex     = regexp.MustCompile(`\b(every\s+)?weekends?\b`)
+        dayRangeRegex     = regexp.MustCompile(`\b(` + naturalDayPattern + `)\s*(?:-|to|through|thru)\s*(` + naturalDayPattern + `)\b`)
+        dayNameRegex      = regexp.MustCompile(`\b` + naturalDayPattern + `\b`)
+        dayOfMonthRegex   = regexp.MustCompile(`\b(\d{1,2})(?:st|nd|rd|th)\b`)
+        firstDayRegex     = regexp.MustCompile(`\bfirst\s+day\b`)
+        monthNameRegex    = regexp.MustCompile(`\b(january|february|march|april|may|june|july|august|september|october|november|december|jan|feb|mar|apr|jun|jul|aug|sept?|oct|nov|dec)\b`)
+        timeListRegex     = regexp.MustCompile(`\s*(?:,|and)\s*`)
+        wordRegex         = regexp.MustCompile(`[a-zA-Z]{3,}`)
+        periodRegex       = regexp.MustCompile(`\b(daily|every\s+day|weekly|every\s+week|monthly|every\s+month|yearly|annually|every\s+year)\b`)
+)
+
+// naturalFillerWords may appear in a phrase without changing its meaning
+var naturalFillerWords = map[string]bool{
+        "every": true, "each": true, "on": true, "at": true, "the": true, "and": true,
+        "of": true, "in": true, "a": true, "run": true, "runs": true, "day": true,
+        "days": true, "month": true, "months": true, "only": true, "o'clock": true,
+        ",": true,
+}
+
+// ParseNaturalSchedule converts phrases such as "every weekday at 9:30" or
+// "every 15 minutes during business hours" into a cron expression. Phrases that
+// standard cron can't represent return a *NotExpressibleError explaining why.
+func ParseNaturalSchedule(phrase string) (NaturalSchedule, error) {
+        text := strings.ToLower(strings.TrimSpace(phrase))
+        text = strings.NewReplacer("a.m.", "am", "p.m.", "pm", ",", " , ").Replace(text)
+        text = strings.Join(strings.Fields(text), " ")
+
+        if err := checkNotExpressible(text); err != nil {
+                return NaturalSchedule{}, err
+        }
+
+        minute, hour, dom, month, dow := "", "", "*", "*", "*"
+        var note, hourNote string
+
+        // consume removes a matched part of the phrase so leftovers can be checked
+        matched := false
+        consume := func(loc []int) {
+                text = text[:loc[0]] + " " + text[loc[1]:]
+                matched = true
+        }
+
+        // Fixed periods like "daily" or "monthly"
+        period := ""
+        if loc := periodRegex.FindStringSubmatchIndex(text); loc != nil {
+                words := strings.Fields(text[loc[2]:loc[3]])
+                period = words[len(words)-1]
+                consume(loc[:2])
+        }
+
+        // Repeating intervals
+        if loc := everyMinutesRegex.FindStringSubmatchIndex(text); loc != nil {
+                n, _ := strconv.Atoi(text[loc[2]:loc[3]])
+                if n < 1 || n > 59 {
+                        return NaturalSchedule{}, &NotExpressibleError{Reason: fmt.Sprintf("a minute interval must be between 1 and 59, got %d; cron steps restart at the top of every hour", n)}
+                }
+                minute = "*/" + strconv.Itoa(n)
+                if 60%n != 0 {
+                        note = fmt.Sprintf("*/%d restarts at the top of each hour, so the gap before :00 is shorter than %d minutes", n, n)
+                }
+                consume(loc[:2])
+        } else if loc := everyMinuteRegex.FindStringIndex(text); loc != nil {
+                minute = "*"
+                consume(loc)
+        }
+
+        if loc := everyHoursRegex.FindStringSubmatchIndex(text); loc != nil {
+                n, _ := strconv.Atoi(text[loc[2]:loc[3]])
+                if n < 1 || n > 23 {
+                        return NaturalSchedule{}, &NotExpressibleError{Reason: fmt.Sprintf("an hour interval must be between 1 and 23, got %d; cron steps restart at midnight", n)}
+                }
+                hour = "*/" + strconv.Itoa(n)
+                if 24%n != 0 {
+                        hourNote = fmt.Sprintf("*/%d restarts at midnight, so the last gap of the day is shorter than %d hours", n, n)
+                }
+                consume(loc[:2])
+        } else if loc := everyHourRegex.FindStringIndex(text); loc != nil {
+                hour = "*"
+                consume(loc)
+        }
+
+        // Time windows keep any hour step, so "every 2 hours" stays every 2 hours.
+        // The step then starts from the window rather than midnight.
+        step := ""
+        if strings.HasPrefix(hour, "*/") {
+                step = hour[1:]
+        }
+        if loc := businessRegex.FindStringIndex(text); loc != nil {
+                hour = "9-16" + step
+                hourNote = ""
+                dow = "1-5"
+                consume(loc)
+        }
+        if loc := betweenRegex.FindStringSubmatchIndex(text); loc != nil {
+                startHour, _, err := parseNaturalTime(text[loc[2]:loc[3]])
+                if err != nil {
+                        return NaturalSchedule{}, err
+                }
+                endHour, endMinute, err := parseNaturalTime(text[loc[4]:loc[5]])
+                if err != nil {
+                        return NaturalSchedule{}, err
+                }
+                // With runs every few minutes, "until 5pm" means the last run
+                // happens in the 4pm hour. Runs on the hour include 5pm itself.
+                if minute != "" && endMinute == 0 && endHour > startHour {
+                        endHour--
+                }
+                if endHour < startHour {
+                        return NaturalSchedule{}, &NotExpressibleError{Reason: "hour ranges can't wrap past midnight; split it into two jobs"}
+                }
+                hour = fmt.Sprintf("%d-%d", startHour, endHour) + step
+                hourNote = ""
+                consume(loc[:2])
+        }
+
+        // Specific times of day
+        if loc := atTimesRegex.FindStringSubmatchIndex(text); loc != nil {
+                var hours []string
+                timeMinute := -1
+                for _, part := range timeListRegex.Split(text[loc[2]:loc[3]], -1) {
+                        if strings.TrimSpace(part) == "" {
+                                continue
+                        }
+                        h, m, err := parseNaturalTime(part)
+                        if err != nil {
+                                return NaturalSchedule{}, err
+                        }
+                        if timeMinute != -1 && m != timeMinute {
+                                return NaturalSchedule{}, &NotExpressibleError{Reason: "times with different minutes (like 9:30 and 5:15) need one cron line each"}
+                        }
+                        timeMinute = m
+                        hours = append(hours, strconv.Itoa(h))
+                }
+                if minute != "" || (hour != "" && hour != "*") {
+                        return NaturalSchedule{}, &NotExpressibleError{Reason: "a fixed time can't be combined with a repeating interval in one cron line"}
+                }
+                minute = strconv.Itoa(timeMinute)
+                hour = strings.Join(hours, ",")
+                consume(loc[:2])
+        }
+
+        // Days of the week
+        if loc := weekdaysRegex.FindStringIndex(text); loc != nil {
+                dow = "1-5"
+                consume(loc)
+        } else if loc := weekendsRegex.FindStringIndex(text); loc != nil {
+                dow = "6,0"
+                consume(loc)
+        } else if loc := dayRangeRegex.FindStringSubmatchIndex(text); loc != nil {
+                dow = fmt.Sprintf("%d-%d", naturalDayNumber(text[loc[2]:loc[3]]), naturalDayNumber(text[loc[4]:loc[5]]))
+                consume(loc[:2])
+        } else if matches := dayNameRegex.FindAllStringIndex(text, -1); matches != nil {
+                var days []string
+                for i := len(matches) - 1; i >= 0; i-- {
+                        days = append([]string{strconv.Itoa(naturalDayNumber(text[matches[i][0]:matches[i][1]]))}, days...)
+                        consume(matches[i])
+                }
+                dow = strings.Join(days, ",")
+        }
+
+        // Days of the month
+        if loc := firstDayRegex.FindStringIndex(text); loc != nil {
+                dom = "1"
+                consume(loc)
+        } else if matches := dayOfMonthRegex.FindAllStringSubmatchIndex(text, -1); matches != nil {
+                var days []string
+                for i := len(matches) - 1; i >= 0; i-- {
+                        n, _ := strconv.Atoi(text[matches[i][2]:matches[i][3]])
+                        if n < 1 || n > 31 {
+                                return NaturalSchedule{}, fmt.Errorf("there is no day %d in a month", n)
+                        }
+                        days = append([]string{strconv.Itoa(n)}, days...)
+                        consume(matches[i][:2])
+                }
+                dom = strings.Join(days, ",")
+        }
+
+        // Months
+        if matches := monthNameRegex.FindAllStringSubmatchIndex(text, -1); matches != nil {
+                var months []string
+                for i := len(matches) - 1; i >= 0; i-- {
+                        name := strings.ToUpper(text[matches[i][2]:matches[i][3]])
+                        months = append([]string{strconv.Itoa(monthBounds.Names[name[:3]])}, months...)
+                        consume(matches[i][:2])
+                }
+                month = strings.Join(months, ",")
+        }
+
+        // Anything left over must be filler, otherwise we've misread the phrase
+        if !matched {
+                return NaturalSchedule{}, fmt.Errorf("no schedule found in %q", phrase)
+        }
+        for _, word := range strings.Fields(text) {
+                if !naturalFillerWords[word] {
+                        return NaturalSchedule{}, fmt.Errorf("don't understand %q", word)
+                }
+        }
+
+        if dom != "*" && dow != "*" {
+                return NaturalSchedule{}, &NotExpressibleError{Reason: "when both a day of the month and a day of the week are set, cron runs on EITHER, not only when both match"}
+        }
+
+        // Fill in defaults from the period, then midnight for anything unset
+        switch period {
+        case "week", "weekly":
+                if dow == "*" {
+                        dow = "0"
+                }
+        case "month", "monthly":
+                if dom == "*" && dow == "*" {
+                        dom = "1"
+                }
+        case "year", "yearly", "annually":
+                if dom == "*" {
+                        dom = "1"
+                }
+                if month == "*" {
+                        month = "1"
+                }
+        }
+        if minute == "" {
+                minute = "0"
+                if hour == "" {
+                        hour = "0"
+                }
+        }
+        if hour == "" {
+                hour = "*"
+        }
+
+        if note == "" {
+                note = hourNote
+        }
+
+        expression := strings.Join([]string{minute, hour, dom, month, dow}, " ")
+        if err := ValidateCronExpression(expression); err != nil {
+                return NaturalSchedule{}, fmt.Errorf("produced an invalid expression %q: %v", expression, err)
+        }
+
+        return NaturalSchedule{Expression: expression, Note: note}, nil
+}
+
+// checkNotExpressible catches common phrases that standard cron can't represent
+func checkNotExpressible(text string) error {
+        if matches := nthWeekdayRegex.FindStringSubmatch(text); matches != nil {
+                return &NotExpressibleError{Reason: fmt.Sprintf(
+                        "cron can't pick the %s %s of a month. Setting both day of month and day of week runs on EITHER match. "+
+                                "Instead schedule it on the days that can match (e.g. 1-7 for the first week) and start the command with a "+
+                                "weekday test such as [ \"$(date +%%u)\" = %d ] &&",
+                        matches[1], strings.TrimSuffix(matches[2], "s"), isoWeekday(naturalDayNumber(matches[2])))}
+        }
+        if lastDayRegex.MatchString(text) {
+                return &NotExpressibleError{Reason: "standard cron has no \"last day of the month\". Schedule it on days 28-31 and start the command with [ \"$(date -d tomorrow +%d)\" = 01 ] &&"}
+        }
+        if secondsRegex.MatchString(text) {
+                return &NotExpressibleError{Reason: "cron's smallest unit is one minute"}
+        }
+        if otherWeekRegex.MatchString(text) {
+                return &NotExpressibleError{Reason: "cron has no week-of-year field, so it can't skip alternate weeks"}
+        }
+        return nil
+}
+
+// parseNaturalTime parses "9", "9:30", "9am", "2:15 pm", "noon" and "midnight"
+func parseNaturalTime(text string) (int, int, error) {
+        text = strings.TrimSpace(text)
+        switch text {
+        case "noon":
+                return 12, 0, nil
+        case "midnight":
+                return 0, 0, nil
+        }
+
+        matches := timeRegex.FindStringSubmatch(text)
+        if matches == nil {
+                return 0, 0, fmt.Errorf("don't understand the time %q", text)
+        }
+        hour, _ := strconv.Atoi(matches[1])
+        minute := 0
+        if matches[2] != "" {
+                minute, _ = strconv.Atoi(matches[2])
+        }
+
+        if minute > 59 || hour > 23 || (matches[3] != "" && (hour < 1 || hour > 12)) {
+                return 0, 0, fmt.Errorf("%q is not a valid time", text)
+        }
+
+        switch matches[3] {
+        case "am":
+                if hour == 12 {
+                        hour = 0
+                }
+        case "pm":
+                if hour < 12 {
+                        hour += 12
+                }
+        }
+        return hour, minute, nil
+}
+
+// naturalDayNumber returns the cron day of week for a day name like "mondays"
+func naturalDayNumber(name string) int {
+        return dowBounds.Names[strings.ToUpper(name[:3])]
+}
+
+// isoWeekday converts a cron day of week to date +%u numbering, where Sunday is 7
+func isoWeekday(day int) int {
+        if day == 0 {
+                return 7
+        }
+        return day
+}
+
+// looksLikeNaturalLanguage reports whether schedule input is worth trying as
+// an English phrase rather than a cron expression
+func looksLikeNaturalLanguage(input string) bool {
+        if strings.HasPrefix(strings.TrimSpace(input), "@") {
+                return false
+        }
+        return wordRegex.MatchString(input) && ValidateCronExpression(input) != nil
+}
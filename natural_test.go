@@ -0,0 +1,80 @@
+package main
+
+import (
+        "errors"
+        "testing"
+)
+
+func TestParseNaturalSchedule(t *testing.T) {
+        tests := []struct {
+                phrase string
+                want   string
+        }{
+                {"every 5 minutes", "*/5 * * * *"},
+                {"hourly", "0 * * * *"},
+                {"daily", "0 0 * * *"},
+                {"weekly", "0 0 * * 0"},
+                {"monthly", "0 0 1 * *"},
+                {"yearly", "0 0 1 1 *"},
+                {"every weekday at 9:30", "30 9 * * 1-5"},
+                {"every wednesday at 9am", "0 9 * * 3"},
+                {"saturdays at noon", "0 12 * * 6"},
+                {"every Thursday at 6 p.m.", "0 18 * * 4"},
+                {"tuesdays and thursdays at 6pm", "0 18 * * 2,4"},
+                {"mon-fri at 8am", "0 8 * * 1-5"},
+                {"weekends at midnight", "0 0 * * 6,0"},
+                {"at 9 and 17", "0 9,17 * * *"},
+                {"1st and 15th at noon", "0 12 1,15 * *"},
+                {"every monday in january", "0 0 * 1 1"},
+                {"every 15 minutes during business hours", "*/15 9-16 * * 1-5"},
+                {"every 15 minutes between 9am and 5pm", "*/15 9-16 * * *"},
+                {"every hour from 9 to 17", "0 9-17 * * *"},
+                {"every 2 hours from 8am to 8pm", "0 8-20/2 * * *"},
+                {"every 3 hours between 6am and 6pm", "0 6-18/3 * * *"},
+                {"every 2 hours during business hours", "0 9-16/2 * * 1-5"},
+        }
+        for _, test := range tests {
+                got, err := ParseNaturalSchedule(test.phrase)
+                if err != nil || got.Expression != test.want {
+                        t.Errorf("ParseNaturalSchedule(%q) = %q, %v, want %q", test.phrase, got.Expression, err, test.want)
+                }
+        }
+}
+
+func TestParseNaturalScheduleNotes(t *testing.T) {
+        if got, _ := ParseNaturalSchedule("every 7 minutes"); got.Note == "" {
+                t.Errorf("every 7 minutes has no note about the shorter gap before :00")
+        }
+        if got, _ := ParseNaturalSchedule("every 5 hours"); got.Note == "" {
+                t.Errorf("every 5 hours has no note about the shorter gap before midnight")
+        }
+        if got, _ := ParseNaturalSchedule("every 5 hours from 8am to 8pm"); got.Note != "" {
+                t.Errorf("every 5 hours from 8am to 8pm has the note %q, but doesn't cross midnight", got.Note)
+        }
+}
+
+func TestParseNaturalScheduleErrors(t *testing.T) {
+        notExpressible := []string{
+                "first monday of the month",
+                "last day of the month",
+                "every 30 seconds",
+                "every other week",
+                "between 10pm and 2am",
+                "at 9:30 and 5:15",
+                "the 1st on mondays",
+                "every 25 hours",
+        }
+        for _, phrase := range notExpressible {
+                _, err := ParseNaturalSchedule(phrase)
+                var notErr *NotExpressibleError
+                if !errors.As(err, &notErr) {
+                        t.Errorf("ParseNaturalSchedule(%q) = %v, want a NotExpressibleError", phrase, err)
+                }
+        }
+
+        for _, phrase := range []string{"every blah", "every monday at 9am sharp", "at 25:00"} {
+                if got, err := ParseNaturalSchedule(phrase); err == nil {
+                        t.Errorf("ParseNaturalSchedule(%q) = %q, want an error", phrase, got.Expression)
+                }
+        }
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+        "encoding/json"
+        "fmt"
+        "io"
+        "strings"
+        "time"
+
+        "gopkg.in/yaml.v3"
+)
+
+// Output formats the list and history subcommands accept
+const (
+        OutputTable = "table"
+        OutputJSON  = "json"
+        OutputYAML  = "yaml"
+)
+
+// outputRunCount is how many upcoming runs each job lists in JSON and YAML
+const outputRunCount = 5
+
+// checkOutputFormat rejects formats other than table, json and yaml
+func checkOutputFormat(format string) error {
+        switch format {
+        case OutputTable, OutputJSON, OutputYAML:
+                return nil
+        }
+        return fmt.Errorf("unknown output format %q, use table, json or yaml", format)
+}
+
+// writeStructured writes v as indented JSON or YAML
+func writeStructured(w io.Writer, format string, v interface{}) error {
+        if format == OutputYAML {
+                encoder := yaml.NewEncoder(w)
+                encoder.SetIndent(2)
+                if err := encoder.Encode(v); err != nil {
+                        return err
+                }
+                return encoder.Close()
+        }
+        encoder := json.NewEncoder(w)
+        encoder.SetIndent("", "  ")
+        return encoder.Encode(v)
+}
+
+// jobRecord is a job as `tuicron list` prints it in JSON and YAML
+type jobRecord struct {
+        Number      int               `json:"number,omitempty" yaml:"number,omitempty"` // 0 for system jobs
+        ID          string            `json:"id" yaml:"id"`
+        Source      string            `json:"source,omitempty" yaml:"source,omitempty"` // File a system job is in
+        User        string            `json:"user,omitempty" yaml:"user,omitempty"`
+        Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+        Expression  string            `json:"expression" yaml:"expression"`
+        Schedule    string            `json:"schedule" yaml:"schedule"`
+        Command     string            `json:"command" yaml:"command"`
+        LogFile     string            `json:"log_file,omitempty" yaml:"log_file,omitempty"`
+        LogPath     string            `json:"log_path,omitempty" yaml:"log_path,omitempty"`
+        Tags        []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+        Env         map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+        Disabled    bool              `json:"disabled" yaml:"disabled"`
+        NoOverlap   bool              `json:"no_overlap" yaml:"no_overlap"`
+        Timeout     string            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+        KillAfter   string            `json:"kill_after,omitempty" yaml:"kill_after,omitempty"`
+        Retry       string            `json:"retry,omitempty" yaml:"retry,omitempty"`
+        Notify      string            `json:"notify,omitempty" yaml:"notify,omitempty"`
+        Stdin       string            `json:"stdin,omitempty" yaml:"stdin,omitempty"`
+        NextRuns    []time.Time       `json:"next_runs" yaml:"next_runs"`
+        LastRun     *time.Time        `json:"last_run,omitempty" yaml:"last_run,omitempty"`
+        LastStatus  string            `json:"last_status" yaml:"last_status"`
+}
+
+// newJobRecord builds the record for the job numbered n in `tuicron list`
+func newJobRecord(job CronJob, n int, now time.Time) jobRecord {
+        record := jobRecord{
+                Number:      n,
+                ID:          JobID(job),
+                Source:      job.SourceFile,
+                User:        job.User,
+                Description: job.Description,
+                Expression:  job.Expression,
+                Schedule:    ParseCronExpression(job.Expression),
+                Command:     job.Command,
+                LogFile:     job.LogFile,
+                Tags:        job.Tags,
+                Env:         envMap(job.Env),
+                Disabled:    job.Disabled,
+                NoOverlap:   job.NoOverlap,
+                Retry:       job.Retry.String(),
+                Notify:      job.Notify,
+                Stdin:       job.Stdin,
+                NextRuns:    []time.Time{},
+                LastStatus:  job.LastStatus,
+        }
+        if job.LogFile != "" {
+                record.LogPath = GetLogFilePath(job.LogFile)
+        }
+        if job.Timeout > 0 {
+                record.Timeout = FormatTimeout(job.Timeout)
+                record.KillAfter = FormatTimeout(job.KillAfter)
+        }
+        if !job.Disabled {
+                if times, err := GetNextRunTimes(job.Expression, now, outputRunCount); err == nil {
+                        record.NextRuns = times
+                }
+        }
+        if !job.LastRun.IsZero() {
+                lastRun := job.LastRun
+                record.LastRun = &lastRun
+        }
+        return record
+}
+
+// envMap turns NAME=value assignments into a map
+func envMap(env []string) map[string]string {
+        if len(env) == 0 {
+                return nil
+        }
+        values := make(map[string]string, len(env))
+        for _, assignment := range env {
+                parts := strings.SplitN(assignment, "=", 2)
+                if len(parts) == 2 {
+                        values[parts[0]] = parts[1]
+                }
+        }
+        return values
+}
+
+// historyRecord is a log line as `tuicron history` prints it in JSON and YAML
+type historyRecord struct {
+        Timestamp *time.Time `json:"timestamp,omitempty" yaml:"timestamp,omitempty"`
+        Status    string     `json:"status" yaml:"status"`
+        Message   string     `json:"message" yaml:"message"`
+}
+
+// newHistoryRecord builds the record for a log entry, taking the timestamp off
+// the front of the message when it has one
+func newHistoryRecord(entry LogEntry) historyRecord {
+        record := historyRecord{Status: entry.Status, Message: entry.Message}
+        if !entry.Timestamp.IsZero() {
+                timestamp := entry.Timestamp
+                record.Timestamp = &timestamp
+                if matches := logLineRegex.FindStringSubmatch(entry.Message); matches != nil {
+                        record.Message = matches[2]
+                }
+        }
+        return record
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+        "fmt"
+        "os"
+        "strings"
+        "time"
+)
+
+// previewRunCount is the number of upcoming runs listed while editing a schedule
+const previewRunCount = 10
+
+// previewLocation returns the extra timezone shown in the run preview. It is
+// read from TUICRON_TZ and defaults to UTC.
+func previewLocation() *time.Location {
+        if name := os.Getenv("TUICRON_TZ"); name != "" {
+                if loc, err := time.LoadLocation(name); err == nil {
+                        return loc
+                }
+        }
+        return time.UTC
+}
+
+// viewRunPreview renders the next fire times and any schedule warnings for the
+// expression currently in the edit form
+func (m Model) viewRunPreview() string {
+        expression := m.inputs[1].Value()
+        if expression == "" || ValidateCronExpression(expression) != nil {
+                return ""
+        }
+
+        var b strings.Builder
+        altZone := previewLocation()
+
+        times, _ := GetNextRunTimes(expression, time.Now(), previewRunCount)
+        header := fmt.Sprintf("Next %d runs (%s)", previewRunCount, time.Now().Format("MST"))
+        if m.showAltZone {
+                header += fmt.Sprintf(" / %s", altZone.String())
+        }
+        b.WriteString(helpStyle.Render(header + ":"))
+        b.WriteString("\n")
+
+        for _, t := range times {
+                line := "  " + t.Format("Mon Jan 2 2006, 15:04")
+                if m.showAltZone {
+                        line += "   " + t.In(altZone).Format("Mon Jan 2 2006, 15:04 MST")
+                }
+                b.WriteString(helpStyle.Render(line))
+                b.WriteString("\n")
+        }
+
+        for _, warning := range ScheduleWarnings(expression) {
+                b.WriteString(cronDescStyle.Render("⚠ " + warning))
+                b.WriteString("\n")
+        }
+
+        return b.String()
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+        "fmt"
+        "math"
+        "strconv"
+        "strings"
+        "time"
+)
+
+// RetryPolicy describes how often a failed run is tried again
+type RetryPolicy struct {
+        Attempts int           // Total attempts including the first, 0 or 1 for no retries
+        Delay    time.Duration // Wait before the first retry
+        Backoff  float64       // Each wait is this many times longer than the last
+}
+
+// Enabled reports whether the policy retries at all
+func (p RetryPolicy) Enabled() bool {
+        return p.Attempts > 1
+}
+
+// String formats the policy the way ParseRetryPolicy reads it
+func (p RetryPolicy) String() string {
+        if !p.Enabled() {
+                return ""
+        }
+        return fmt.Sprintf("%d, %s, x%s", p.Attempts, FormatTimeout(p.Delay), formatFactor(p.Backoff))
+}
+
+// Describe explains the policy in words
+func (p RetryPolicy) Describe() string {
+        if !p.Enabled() {
+                return "None"
+        }
+        text := fmt.Sprintf("Up to %d attempts, %s apart", p.Attempts, FormatTimeout(p.Delay))
+        if p.Backoff > 1 {
+                text += fmt.Sprintf(", each wait %s times longer", formatFactor(p.Backoff))
+        }
+        return text
+}
+
+// ParseRetryPolicy parses "attempts, delay, xfactor" such as "3, 30s, x2". The
+// delay defaults to 60s and the backoff factor to 1.
+func ParseRetryPolicy(value string) (RetryPolicy, error) {
+        policy := RetryPolicy{Delay: time.Minute, Backoff: 1}
+        parts := strings.Split(value, ",")
+        if len(parts) > 3 {
+                return RetryPolicy{}, fmt.Errorf("expected attempts, delay and backoff, e.g. 3, 30s, x2")
+        }
+
+        attempts, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+        if err != nil || attempts < 1 {
+                return RetryPolicy{}, fmt.Errorf("attempts must be a whole number of at least 1")
+        }
+        policy.Attempts = attempts
+
+        if len(parts) > 1 {
+                if policy.Delay, err = ParseTimeout(strings.TrimSpace(parts[1])); err != nil {
+                        return RetryPolicy{}, fmt.Errorf("delay %v", err)
+                }
+        }
+
+        if len(parts) > 2 {
+                factor := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(parts[2])), "x")
+                if policy.Backoff, err = strconv.ParseFloat(factor, 64); err != nil || !(policy.Backoff >= 1) || math.IsInf(policy.Backoff, 0) {
+                        return RetryPolicy{}, fmt.Errorf("backoff must be a factor of at least 1 such as x2 or x1.5")
+                }
+        }
+
+        return policy, nil
+}
+
+// NextDelay returns the wait after delay, rounded to whole seconds
+func (p RetryPolicy) NextDelay(delay time.Duration) time.Duration {
+        if p.Backoff <= 1 {
+                return delay
+        }
+        return time.Duration(float64(delay) * p.Backoff).Round(time.Second)
+}
+
+// formatFactor formats a backoff factor without trailing zeros, e.g. 2 or 1.5
+func formatFactor(factor float64) string {
+        return strconv.FormatFloat(factor, 'f', -1, 64)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+        "os"
+        "path/filepath"
+        "strings"
+        "testing"
+        "time"
+)
+
+func TestParseRetryPolicy(t *testing.T) {
+        tests := []struct {
+                value string
+                want  string
+        }{
+                {"3, 30s, x2", "3, 30s, x2"},
+                {"4, 1m, x1.5", "4, 1m, x1.5"},
+                {"2", "2, 1m, x1"},
+                {"5, 10s", "5, 10s, x1"},
+        }
+        for _, tt := range tests {
+                policy, err := ParseRetryPolicy(tt.value)
+                if err != nil {
+                        t.Errorf("ParseRetryPolicy(%q) failed: %v", tt.value, err)
+                        continue
+                }
+                if got := policy.String(); got != tt.want {
+                        t.Errorf("ParseRetryPolicy(%q).String() = %q, want %q", tt.value, got, tt.want)
+                }
+        }
+
+        for _, value := range []string{"0", "3, 30s, x0.5", "3, 30s, xinf", "3, 30s, xnan", "3, 30s, x2, 1"} {
+                if _, err := ParseRetryPolicy(value); err == nil {
+                        t.Errorf("ParseRetryPolicy(%q) succeeded, want an error", value)
+                }
+        }
+}
+
+func TestRetryNextDelay(t *testing.T) {
+        policy := RetryPolicy{Attempts: 4, Delay: 30 * time.Second, Backoff: 1.5}
+        delay := policy.Delay
+        var got []string
+        for i := 0; i < 3; i++ {
+                got = append(got, FormatTimeout(delay))
+                delay = policy.NextDelay(delay)
+        }
+        if want := "30s 45s 68s"; strings.Join(got, " ") != want {
+                t.Errorf("delays = %q, want %q", strings.Join(got, " "), want)
+        }
+}
+
+func TestRetryReplaysInput(t *testing.T) {
+        t.Setenv("HOME", t.TempDir())
+        out := filepath.Join(t.TempDir(), "input")
+        t.Setenv("OUT", out)
+
+        code := execWithInput([]string{"--job", "retry-test", "--retry", "2, 1s", "--", `cat >> "$OUT"; exit 3`}, strings.NewReader("hello\n"))
+        if code != 3 {
+                t.Errorf("exit code = %d, want 3", code)
+        }
+        data, err := os.ReadFile(out)
+        if err != nil {
+                t.Fatal(err)
+        }
+        if string(data) != "hello\nhello\n" {
+                t.Errorf("attempts read %q, want %q", data, "hello\nhello\n")
+        }
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+        "bytes"
+        "errors"
+        "flag"
+        "fmt"
+        "io"
+        "os"
+        "os/exec"
+        "path/filepath"
+        "strconv"
+        "strings"
+        "syscall"
+        "time"
+)
+
+// execOptions are the settings cron passes to `tuicron exec` for one job
+type execOptions struct {
+        Job       string
+        LogFile   string
+        Lock      bool
+        Timeout   time.Duration
+        KillAfter time.Duration
+        Retry     RetryPolicy
+        Notify    string // Shell command run when the job fails or times out
+        Command   string
+}
+
+// parseExecArgs reads the arguments of `tuicron exec`. Everything after "--"
+// is the command, which is run with sh -c.
+func parseExecArgs(args []string) (execOptions, error) {
+        var opts execOptions
+        var retry string
+
+        flags := flag.NewFlagSet("exec", flag.ContinueOnError)
+        flags.SetOutput(io.Discard)
+        flags.StringVar(&opts.Job, "job", "", "job id, names the lock file")
+        flags.StringVar(&opts.LogFile, "log", "", "log file name under ~/.cron_history")
+        flags.BoolVar(&opts.Lock, "lock", false, "skip the run while the previous one is still going")
+        flags.DurationVar(&opts.Timeout, "timeout", 0, "stop the job after this long")
+        flags.DurationVar(&opts.KillAfter, "kill-after", defaultKillAfter, "grace period between SIGTERM and SIGKILL")
+        flags.StringVar(&retry, "retry", "", "retry policy: attempts, delay, backoff factor")
+        flags.StringVar(&opts.Notify, "notify", "", "command to run when the job fails")
+
+        if err := flags.Parse(args); err != nil {
+                return opts, err
+        }
+        if opts.Job == "" {
+                return opts, fmt.Errorf("--job is required")
+        }
+
+        // Both end up in file names under ~/.cron_history
+        if err := ValidateLogFileName(opts.Job); err != nil {
+                return opts, fmt.Errorf("invalid --job: %v", err)
+        }
+        if opts.LogFile != "" {
+                if err := ValidateLogFileName(opts.LogFile); err != nil {
+                        return opts, fmt.Errorf("invalid --log: %v", err)
+                }
+        }
+        if flags.NArg() == 0 {
+                return opts, fmt.Errorf("no command given after --")
+        }
+        opts.Command = strings.Join(flags.Args(), " ")
+
+        if retry != "" {
+                policy, err := ParseRetryPolicy(retry)
+                if err != nil {
+                        return opts, fmt.Errorf("invalid --retry: %v", err)
+                }
+                opts.Retry = policy
+        }
+        if opts.Timeout == 0 {
+                opts.KillAfter = 0
+        }
+
+        return opts, nil
+}
+
+// execArgs returns the arguments that make `tuicron exec` run a job
+func execArgs(job CronJob) []string {
+        args := []string{"exec", "--job", JobID(job)}
+        if job.LogFile != "" {
+                args = append(args, "--log", job.LogFile)
+        }
+        if job.NoOverlap {
+                args = append(args, "--lock")
+        }
+        if job.Timeout > 0 {
+                args = append(args, "--timeout", FormatTimeout(job.Timeout))
+                if job.KillAfter > 0 && job.KillAfter != defaultKillAfter {
+                        args = append(args, "--kill-after", FormatTimeout(job.KillAfter))
+                }
+        }
+        if job.Retry.Enabled() {
+                args = append(args, "--retry", job.Retry.String())
+        }
+        if job.Notify != "" {
+                args = append(args, "--notify", job.Notify)
+        }
+        return append(args, "--", job.Command)
+}
+
+// AddRunnerToCommand builds the crontab command that runs a job through
+// `tuicron exec`
+func AddRunnerToCommand(runner string, job CronJob) string {
+        words := []string{shellWord(runner)}
+        for _, arg := range execArgs(job) {
+                words = append(words, shellWord(arg))
+        }
+        return strings.Join(words, " ")
+}
+
+// parseRunnerCommand recognises a crontab command that calls `tuicron exec`
+func parseRunnerCommand(command string) (execOptions, bool) {
+        if !strings.Contains(command, " exec ") {
+                return execOptions{}, false
+        }
+        words, err := splitShellWords(command)
+        if err != nil || len(words) < 2 || filepath.Base(words[0]) != "tuicron" || words[1] != "exec" {
+                return execOptions{}, false
+        }
+        opts, err := parseExecArgs(words[2:])
+        if err != nil {
+                return execOptions{}, false
+        }
+        return opts, true
+}
+
+// RunnerPath returns the tuicron binary cron should call to run jobs, or ""
+// when there isn't a stable one, such as under go run. Jobs can't be saved
+// then.
+func RunnerPath() string {
+        if path, err := os.Executable(); err == nil && !isTemporaryBinary(path) && filepath.Base(path) == "tuicron" {
+                return path
+        }
+        if path, err := exec.LookPath("tuicron"); err == nil {
+                if abs, err := filepath.Abs(path); err == nil {
+                        return abs
+                }
+        }
+        return ""
+}
+
+// isTemporaryBinary reports whether path is a throwaway build from go run or
+// go test
+func isTemporaryBinary(path string) bool {
+        return strings.HasPrefix(path, os.TempDir()) || strings.Contains(path, "go-build")
+}
+
+// runExec implements `tuicron exec`, returning the process exit code
+func runExec(args []string) int {
+        return execWithInput(args, os.Stdin)
+}
+
+// execWithInput runs a job the way `tuicron exec` does, with stdin as the
+// command's input
+func execWithInput(args []string, stdin io.Reader) int {
+        opts, err := parseExecArgs(args)
+        if err != nil {
+                fmt.Fprintf(os.Stderr, "tuicron exec: %v\n", err)
+                fmt.Fprintln(os.Stderr, "usage: tuicron exec --job <id> [--log <name>] [--lock] [--timeout <d>] [--kill-after <d>] [--retry <policy>] [--notify <cmd>] -- <command>")
+                return 2
+        }
+
+        // Without a log file the command's output goes to cron as usual and
+        // only problems are reported, on stderr
+        var output io.Writer = os.Stdout
+        var progress io.Writer = io.Discard
+        var problems io.Writer = os.Stderr
+        if opts.LogFile != "" {
+                CreateLogDir()
+                file, err := os.OpenFile(GetLogFilePath(opts.LogFile), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+                if err != nil {
+                        fmt.Fprintf(os.Stderr, "tuicron exec: failed to open log file: %v\n", err)
+                } else {
+                        defer file.Close()
+                        output, progress, problems = file, file, file
+                }
+        }
+        logf := func(w io.Writer, format string, args ...interface{}) {
+                fmt.Fprintf(w, "%s - %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+        }
+
+        if opts.Lock {
+                CreateLockDir()
+                lock, err := os.OpenFile(filepath.Join(GetLockDir(), opts.Job+".lock"), os.O_CREATE|os.O_RDONLY, 0644)
+                if err != nil {
+                        logf(problems, "Error opening lock file: %v", err)
+                        return 1
+                }
+                defer lock.Close()
+                if err := syscall.Flock(int(lock.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+                        logf(problems, "Skipped, the previous run is still going")
+                        return 0
+                }
+        }
+
+        logf(progress, "Starting job")
+        started := time.Now()
+
+        attempts := 1
+        if opts.Retry.Enabled() {
+                attempts = opts.Retry.Attempts
+        }
+        delay := opts.Retry.Delay
+
+        // Each attempt gets the same input, so it is read up front when the
+        // command may run more than once
+        input := func() io.Reader { return stdin }
+        if attempts > 1 && stdin != nil {
+                data, err := io.ReadAll(stdin)
+                if err != nil {
+                        logf(problems, "Error reading input: %v", err)
+                }
+                input = func() io.Reader { return bytes.NewReader(data) }
+        }
+
+        var code int
+        var timedOut bool
+        for attempt := 1; ; attempt++ {
+                code, timedOut = runAttempt(opts, input(), output)
+                if timedOut {
+                        logf(problems, "Job timed out after %s", FormatTimeout(opts.Timeout))
+                }
+                if code == 0 || attempt >= attempts {
+                        if code != 0 && attempts > 1 {
+                                logf(problems, "Attempt %d of %d failed with exit code %d, giving up", attempt, attempts, code)
+                        }
+                        break
+                }
+                logf(problems, "Attempt %d of %d failed with exit code %d, retrying in %s", attempt, attempts, code, FormatTimeout(delay))
+                time.Sleep(delay)
+                delay = opts.Retry.NextDelay(delay)
+        }
+
+        elapsed := time.Since(started).Round(time.Second)
+        if code == 0 {
+                logf(progress, "Job finished in %s", elapsed)
+                return 0
+        }
+        if !timedOut {
+                logf(problems, "Job failed after %s with exit code %d", elapsed, code)
+        }
+
+        if opts.Notify != "" {
+                status := "failed"
+                if timedOut {
+                        status = "timed out"
+                }
+                notify := exec.Command("sh", "-c", opts.Notify)
+                notify.Stdout, notify.Stderr = problems, problems
+                notify.Env = append(os.Environ(),
+                        "TUICRON_JOB="+opts.Job,
+                        "TUICRON_STATUS="+status,
+                        "TUICRON_EXIT_CODE="+strconv.Itoa(code),
+                        "TUICRON_COMMAND="+opts.Command,
+                )
+                if opts.LogFile != "" {
+                        notify.Env = append(notify.Env, "TUICRON_LOG="+GetLogFilePath(opts.LogFile))
+                }
+                if err := notify.Run(); err != nil {
+                        logf(problems, "Error running notify command: %v", err)
+                }
+        }
+
+        return code
+}
+
+// runAttempt runs the command once, stopping it if it outlives the timeout.
+// It returns the exit code, which is 124 for a timeout as with timeout(1).
+func runAttempt(opts execOptions, stdin io.Reader, output io.Writer) (int, bool) {
+        cmd := exec.Command("sh", "-c", opts.Command)
+        cmd.Stdin = stdin
+        cmd.Stdout, cmd.Stderr = output, output
+
+        // Run in its own process group so a timeout stops everything it started
+        cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+
+        if err := cmd.Start(); err != nil {
+                fmt.Fprintf(output, "%s - Error starting job: %v\n", time.Now().Format("2006-01-02 15:04:05"), err)
+                return 127, false
+        }
+
+        done := make(chan error, 1)
+        go func() { done <- cmd.Wait() }()
+
+        var deadline <-chan time.Time
+        if opts.Timeout > 0 {
+                deadline = time.After(opts.Timeout)
+        }
+
+        select {
+        case err := <-done:
+                return exitCode(err), false
+        case <-deadline:
+                syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+                select {
+                case <-done:
+                case <-time.After(opts.KillAfter):
+                        syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+                        <-done
+                }
+                return 124, true
+        }
+}
+
+// exitCode turns the result of Wait into a shell style exit code
+func exitCode(err error) int {
+        if err == nil {
+                return 0
+        }
+        var exitErr *exec.ExitError
+        if errors.As(err, &exitErr) {
+                if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+                        return 128 + int(status.Signal())
+                }
+                return exitErr.ExitCode()
+        }
+        return 1
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+        "regexp"
+        "strings"
+        "unicode"
+
+        "github.com/charmbracelet/bubbles/table"
+        "github.com/charmbracelet/lipgloss"
+        "github.com/mattn/go-runewidth"
+)
+
+// searchField is one searchable attribute of a cron job
+type searchField struct {
+        Label string
+        Value string
+}
+
+// searchMatch describes where a query matched within a field
+type searchMatch struct {
+        Field     searchField
+        Positions map[int]bool // Rune offsets of matched characters
+        Score     int
+}
+
+var matchStyle = lipgloss.NewStyle().
+        Foreground(lipgloss.Color("229")).
+        Background(lipgloss.Color("57")).
+        Bold(true)
+
+// selectedRowStyle colours the table row under the cursor
+var selectedRowStyle = lipgloss.NewStyle().
+        Foreground(lipgloss.Color("229")).
+        Background(lipgloss.Color("57"))
+
+// selectedMatchStyle marks matched characters on the selected row, which is
+// already in matchStyle's colours
+var selectedMatchStyle = matchStyle.Copy().Underline(true)
+
+// searchColumns maps the search fields the table shows to their column in
+// jobColumns
+var searchColumns = map[string]int{"Description": 0, "Expression": 2, "Command": 5}
+
+// ansiRegex matches the styling escape codes in rendered text
+var ansiRegex = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// jobSearchFields returns the fields of a job that the table search looks at
+func jobSearchFields(job CronJob) []searchField {
+        return []searchField{
+                {Label: "Description", Value: job.Description},
+                {Label: "Expression", Value: job.Expression},
+                {Label: "Command", Value: StripLoggingFromCommand(job.Command)},
+                {Label: "Log File", Value: job.LogFile},
+                {Label: "Tags", Value: strings.Join(job.Tags, ", ")},
+                {Label: "Unit", Value: job.TimerUnit},
+        }
+}
+
+// fuzzyMatch reports whether all characters of pattern appear in text in order,
+// ignoring case. It returns a score that favours consecutive characters and
+// matches at the start of words, along with the rune offsets that matched.
+func fuzzyMatch(pattern, text string) (int, []int, bool) {
+        p := []rune(strings.ToLower(pattern))
+        t := []rune(strings.ToLower(text))
+        if len(p) == 0 {
+                return 0, nil, true
+        }
+
+        var positions []int
+        score := 0
+        pi := 0
+        last := -2
+        for ti := 0; ti < len(t) && pi < len(p); ti++ {
+                if t[ti] != p[pi] {
+                        continue
+                }
+
+                score++
+                if ti == last+1 {
+                        score += 5 // Consecutive characters
+                }
+                if ti == 0 || (!unicode.IsLetter(t[ti-1]) && !unicode.IsDigit(t[ti-1])) {
+                        score += 3 // Start of a word
+                }
+
+                positions = append(positions, ti)
+                last = ti
+                pi++
+        }
+
+        if pi < len(p) {
+                return 0, nil, false
+        }
+        return score, positions, true
+}
+
+// matchJob fuzzy-matches a search query against a job. Every whitespace
+// separated term of the query has to match at least one field. The returned
+// matches are ordered like jobSearchFields and only include fields that matched.
+func matchJob(job CronJob, query string) ([]searchMatch, bool) {
+        terms := strings.Fields(query)
+        if len(terms) == 0 {
+                return nil, true
+        }
+
+        fields := jobSearchFields(job)
+        matches := make([]searchMatch, len(fields))
+        for i, field := range fields {
+                matches[i] = searchMatch{Field: field, Positions: map[int]bool{}}
+        }
+
+        for _, term := range terms {
+                found := false
+                for i, field := range fields {
+                        score, positions, ok := fuzzyMatch(term, field.Value)
+                        if !ok {
+                                continue
+                        }
+                        found = true
+                        matches[i].Score += score
+                        for _, pos := range positions {
+                                matches[i].Positions[pos] = true
+                        }
+                }
+                if !found {
+                        return nil, false
+                }
+        }
+
+        var result []searchMatch
+        for _, match := range matches {
+                if len(match.Positions) > 0 {
+                        result = append(result, match)
+                }
+        }
+        return result, true
+}
+
+// highlightMatch renders a field value with its matched characters highlighted
+func highlightMatch(match searchMatch) string {
+        var b strings.Builder
+        for i, r := range []rune(match.Field.Value) {
+                if match.Positions[i] {
+                        b.WriteString(matchStyle.Render(string(r)))
+                } else {
+                        b.WriteRune(r)
+                }
+        }
+        return b.String()
+}
+
+// columnShown reports whether the table currently shows a search field
+func (m Model) columnShown(label string) bool {
+        key, ok := searchColumns[label]
+        if !ok {
+                return false
+        }
+        for _, shown := range m.columns {
+                if shown == key {
+                        return true
+                }
+        }
+        return false
+}
+
+// highlightTableMatches highlights the characters the search matched in the
+// rendered table. The table pads and truncates cells without regard to
+// styling, so each row is found in its output by its plain text and drawn
+// again here with the highlights.
+func (m Model) highlightTableMatches(view string) string {
+        if strings.TrimSpace(m.search.Value()) == "" {
+                return view
+        }
+
+        _, columns := tableColumns(m.tableWidth(), m.sortColumn, m.sortDesc)
+        rows := m.table.Rows()
+        plain := map[string][]int{}
+        for i, row := range rows {
+                text := renderTableRow(row, columns, nil, false)
+                plain[text] = append(plain[text], i)
+        }
+
+        lines := strings.Split(view, "\n")
+        for n, line := range lines {
+                text := ansiRegex.ReplaceAllString(line, "")
+                candidates := plain[text]
+                if len(candidates) == 0 {
+                        continue
+                }
+
+                // Identical rows render alike, only the cursor's is styled
+                selected := text != line
+                row := candidates[0]
+                for _, candidate := range candidates {
+                        if (candidate == m.table.Cursor()) == selected {
+                                row = candidate
+                                break
+                        }
+                }
+                if row >= len(m.visible) {
+                        continue
+                }
+                lines[n] = renderTableRow(rows[row], columns, m.cellMatches(m.visible[row], rows[row]), selected)
+        }
+        return strings.Join(lines, "\n")
+}
+
+// cellMatches returns the rune offsets the search matched in each cell of a
+// job's table row
+func (m Model) cellMatches(jobIndex int, row table.Row) []map[int]bool {
+        positions := make([]map[int]bool, len(row))
+        matches, _ := matchJob(m.jobs[jobIndex], m.search.Value())
+        for _, match := range matches {
+                key, ok := searchColumns[match.Field.Label]
+                if !ok {
+                        continue
+                }
+                for n, shown := range m.columns {
+                        if shown != key || n >= len(row) {
+                                continue
+                        }
+
+                        // The cell may start with a lint badge and end in an
+                        // ellipsis, so only runes that line up with the value count
+                        cell := []rune(row[n])
+                        offset := 0
+                        if key == 0 && strings.HasPrefix(row[n], lintBadge) {
+                                offset = len([]rune(lintBadge))
+                        }
+                        value := []rune(match.Field.Value)
+                        positions[n] = map[int]bool{}
+                        for pos := range match.Positions {
+                                i := pos + offset
+                                if i < len(cell) && pos < len(value) && cell[i] == value[pos] {
+                                        positions[n][i] = true
+                                }
+                        }
+                }
+        }
+        return positions
+}
+
+// renderTableRow draws a row the way the table does, padding each cell to its
+// column, with the runes in matched highlighted
+func renderTableRow(row table.Row, columns []table.Column, matched []map[int]bool, selected bool) string {
+        highlight := matchStyle
+        if selected {
+                highlight = selectedMatchStyle
+        }
+
+        // Unmatched text is gathered into runs so the selected row is styled
+        // a run at a time rather than a rune at a time
+        var b, run strings.Builder
+        flush := func() {
+                if run.Len() > 0 {
+                        if selected {
+                                b.WriteString(selectedRowStyle.Render(run.String()))
+                        } else {
+                                b.WriteString(run.String())
+                        }
+                        run.Reset()
+                }
+        }
+        for n, cell := range row {
+                if n >= len(columns) {
+                        break
+                }
+                run.WriteString(" ")
+                for i, r := range []rune(cell) {
+                        if n < len(matched) && matched[n][i] {
+                                flush()
+                                b.WriteString(highlight.Render(string(r)))
+                        } else {
+                                run.WriteRune(r)
+                        }
+                }
+                if padding := columns[n].Width - runewidth.StringWidth(cell); padding > 0 {
+                        run.WriteString(strings.Repeat(" ", padding))
+                }
+                run.WriteString(" ")
+        }
+        flush()
+        return b.String()
+}
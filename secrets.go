@@ -0,0 +1,351 @@
+package main
+
+import (
+        "fmt"
+        "os"
+        "path/filepath"
+        "regexp"
+        "sort"
+        "strings"
+
+        "github.com/charmbracelet/bubbletea"
+        "github.com/charmbracelet/lipgloss"
+)
+
+// secretMatch is something in a command that looks like a credential
+type secretMatch struct {
+        Kind  string // What it looks like, such as "an AWS access key"
+        Name  string // Variable the value moves to in the env file
+        Value string // The secret itself, without quotes
+        Start int    // Byte offsets of the value in the command, including any quotes
+        End   int
+}
+
+// secretPattern finds one kind of credential. The first capture group is the
+// secret, and a second group, when present, names its variable.
+type secretPattern struct {
+        Kind  string
+        Name  string // Variable name when the pattern doesn't capture one
+        Regex *regexp.Regexp
+        Only  *regexp.Regexp // Only look when the command also matches this
+}
+
+var secretPatterns = []secretPattern{
+        {
+                Kind:  "a password in %s",
+                Regex: regexp.MustCompile(`(?:^|[\s;&|(])([A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_KEY|APIKEY)[A-Z0-9_]*|[A-Z0-9_]+_PWD)=('[^']*'|"[^"]*"|[^\s;&|'"]+)`),
+        },
+        {
+                Kind:  "a password option",
+                Name:  "TUICRON_PASSWORD",
+                Regex: regexp.MustCompile(`--(?:password|passwd|token|api-key|secret)=('[^']*'|"[^"]*"|[^\s;&|'"]+)`),
+        },
+        {
+                Kind:  "an inline MySQL password",
+                Name:  "MYSQL_PWD",
+                Regex: regexp.MustCompile(`(?:^|\s)-p('[^']+'|"[^"]+"|[^\s;&|'"]{2,})`),
+                Only:  regexp.MustCompile(`\b(?:mysql|mysqldump|mysqladmin|mariadb|mariadb-dump)\b`),
+        },
+        {
+                Kind:  "an AWS access key",
+                Name:  "AWS_ACCESS_KEY_ID",
+                Regex: regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`),
+        },
+        {
+                Kind:  "a bearer token",
+                Name:  "TUICRON_TOKEN",
+                Regex: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{8,})`),
+        },
+        {
+                Kind:  "an API token",
+                Name:  "TUICRON_TOKEN",
+                Regex: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{30,}|github_pat_[A-Za-z0-9_]{30,}|glpat-[A-Za-z0-9_-]{20,}|xox[abprs]-[A-Za-z0-9-]{10,}|sk_live_[A-Za-z0-9]{16,})`),
+        },
+        {
+                Kind:  "a password in a URL",
+                Name:  "TUICRON_URL_PASSWORD",
+                Regex: regexp.MustCompile(`[A-Za-z][A-Za-z0-9+.-]*://[^\s/:@'"]+:([^\s/@'"]+)@`),
+        },
+}
+
+// FindSecrets returns the things in a command that look like credentials, in
+// the order they appear. Values that are already variable references are
+// left alone.
+func FindSecrets(command string) []secretMatch {
+        var secrets []secretMatch
+        names := map[string]string{}
+
+        overlaps := func(start, end int) bool {
+                for _, s := range secrets {
+                        if start < s.End && end > s.Start {
+                                return true
+                        }
+                }
+                return false
+        }
+
+        for _, pattern := range secretPatterns {
+                if pattern.Only != nil && !pattern.Only.MatchString(command) {
+                        continue
+                }
+                for _, loc := range pattern.Regex.FindAllStringSubmatchIndex(command, -1) {
+                        kind, name := pattern.Kind, pattern.Name
+                        start, end := loc[2], loc[3]
+                        if len(loc) > 4 && loc[4] >= 0 {
+                                name = command[loc[2]:loc[3]]
+                                kind = fmt.Sprintf(pattern.Kind, name)
+                                start, end = loc[4], loc[5]
+                        }
+
+                        value := command[start:end]
+                        if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') {
+                                value = value[1 : len(value)-1]
+                        }
+                        if value == "" || strings.HasPrefix(value, "$") || overlaps(start, end) {
+                                continue
+                        }
+
+                        // Different values that would share a variable get numbered names
+                        base := name
+                        for n := 2; names[name] != "" && names[name] != value; n++ {
+                                name = fmt.Sprintf("%s_%d", base, n)
+                        }
+                        names[name] = value
+
+                        secrets = append(secrets, secretMatch{Kind: kind, Name: name, Value: value, Start: start, End: end})
+                }
+        }
+
+        sort.Slice(secrets, func(i, j int) bool { return secrets[i].Start < secrets[j].Start })
+        return secrets
+}
+
+// maskSecret hides most of a secret so it can be shown on screen
+func maskSecret(value string) string {
+        if len(value) <= 4 {
+                return strings.Repeat("*", len(value))
+        }
+        return value[:2] + strings.Repeat("*", 6) + value[len(value)-2:]
+}
+
+// GetSecretsDir returns the directory holding the env files secrets are moved to
+func GetSecretsDir() string {
+        homeDir, _ := os.UserHomeDir()
+        return filepath.Join(homeDir, ".tuicron_secrets")
+}
+
+// sourcedEnvRegex matches the env file a command sources before it runs
+var sourcedEnvRegex = regexp.MustCompile(`^\. ('(?:[^']|'\\'')*'|[^\s']+) && `)
+
+// SecretEnvPath returns the env file a job's secrets are moved to. Once a
+// command sources one from the secrets directory it keeps using that file, as
+// its job id changes when the secrets are taken out of the command.
+func SecretEnvPath(job CronJob) string {
+        if matches := sourcedEnvRegex.FindStringSubmatch(job.Command); matches != nil {
+                path := shellUnquote(matches[1])
+                if filepath.Dir(path) == GetSecretsDir() {
+                        return path
+                }
+        }
+        return filepath.Join(GetSecretsDir(), JobID(job)+".env")
+}
+
+// quoteAt reports which quote, if any, is open at offset pos of a command
+func quoteAt(command string, pos int) byte {
+        var quote byte
+        for i := 0; i < pos && i < len(command); i++ {
+                c := command[i]
+                switch {
+                case quote == 0 && (c == '\'' || c == '"'):
+                        quote = c
+                case quote == c:
+                        quote = 0
+                case c == '\\' && quote != '\'':
+                        i++
+                }
+        }
+        return quote
+}
+
+// ReplaceSecrets swaps each secret in a command for a reference to its
+// variable, quoted to suit where it appears, and sources envPath first
+func ReplaceSecrets(command, envPath string, secrets []secretMatch) string {
+        var b strings.Builder
+        last := 0
+        for _, s := range secrets {
+                b.WriteString(command[last:s.Start])
+                switch quoteAt(command, s.Start) {
+                case '\'':
+                        b.WriteString(`'"${` + s.Name + `}"'`)
+                case '"':
+                        b.WriteString("${" + s.Name + "}")
+                default:
+                        b.WriteString(`"${` + s.Name + `}"`)
+                }
+                last = s.End
+        }
+        b.WriteString(command[last:])
+
+        source := ". " + shellWord(envPath) + " && "
+        if strings.HasPrefix(command, source) {
+                return b.String()
+        }
+        return source + b.String()
+}
+
+// WriteSecretEnvFile writes the secrets to an env file only the user can read.
+// Lines already setting one of the names are replaced, others are kept.
+func WriteSecretEnvFile(envPath string, secrets []secretMatch) error {
+        if err := os.MkdirAll(filepath.Dir(envPath), 0700); err != nil {
+                return fmt.Errorf("failed to create secrets directory: %v", err)
+        }
+        existing, err := os.ReadFile(envPath)
+        if err != nil && !os.IsNotExist(err) {
+                return fmt.Errorf("failed to read env file: %v", err)
+        }
+
+        values := map[string]string{}
+        var names []string
+        for _, s := range secrets {
+                if _, ok := values[s.Name]; !ok {
+                        names = append(names, s.Name)
+                }
+                values[s.Name] = s.Value
+        }
+
+        var b strings.Builder
+        for _, line := range strings.SplitAfter(string(existing), "\n") {
+                if line == "" {
+                        continue
+                }
+                if name, _, ok := strings.Cut(line, "="); ok {
+                        if _, replaced := values[strings.TrimSpace(name)]; replaced {
+                                continue
+                        }
+                }
+                b.WriteString(line)
+                if !strings.HasSuffix(line, "\n") {
+                        b.WriteString("\n")
+                }
+        }
+        for _, name := range names {
+                fmt.Fprintf(&b, "%s=%s\n", name, shellQuote(values[name]))
+        }
+
+        file, err := os.OpenFile(envPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+        if err != nil {
+                return fmt.Errorf("failed to open env file: %v", err)
+        }
+        defer file.Close()
+
+        // An existing file keeps its mode, so tighten it in case it was loose
+        if err := file.Chmod(0600); err != nil {
+                return fmt.Errorf("failed to restrict env file: %v", err)
+        }
+        if _, err := file.WriteString(b.String()); err != nil {
+                return fmt.Errorf("failed to write env file: %v", err)
+        }
+        return nil
+}
+
+// updateSecrets handles key presses in the dialog shown when a job being
+// saved contains secrets
+func (m Model) updateSecrets(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+        switch msg.String() {
+        case "ctrl+c", "esc", "q":
+                m.mode = ViewEdit
+                return m, nil
+
+        case "left", "right", "tab":
+                m.secretChoice = 1 - m.secretChoice
+                return m, nil
+
+        case "enter":
+                m.mode = ViewEdit
+                envPath := m.secretEnvPath()
+                command := m.inputs[2].Value()
+                if m.secretChoice == 0 {
+                        m.inputs[2].SetValue(ReplaceSecrets(StripPercentEscapes(command), envPath, m.secrets))
+                }
+
+                // Save without checking again, then check the next job as usual
+                m.secretsChecked = true
+                model, cmd := m.saveJob()
+                saved := model.(Model)
+                saved.secretsChecked = false
+                if m.secretChoice == 0 {
+                        if saved.mode != ViewTable {
+                                // Nothing was saved, so the secrets stay in the command
+                                saved.inputs[2].SetValue(command)
+                                return saved, cmd
+                        }
+                        // The env file is only written once the crontab refers to it
+                        if err := WriteSecretEnvFile(envPath, m.secrets); err != nil {
+                                saved.message = ""
+                                saved.error = fmt.Sprintf("Job saved, but moving secrets to %s failed: %v", envPath, err)
+                                return saved, cmd
+                        }
+                        saved.message += fmt.Sprintf(", secrets moved to %s", envPath)
+                }
+                return saved, cmd
+        }
+        return m, nil
+}
+
+// secretEnvPath returns the env file for the job in the edit form
+func (m Model) secretEnvPath() string {
+        return SecretEnvPath(CronJob{LogFile: m.inputs[3].Value(), Command: StripPercentEscapes(m.inputs[2].Value())})
+}
+
+// viewSecrets renders the dialog offering to move secrets out of the crontab
+func (m Model) viewSecrets() string {
+        var b strings.Builder
+
+        b.WriteString(titleStyle.Render("Possible Secrets in Command"))
+        b.WriteString("\n\n")
+        b.WriteString("The crontab is readable by anything running as you and ends up in backups, so")
+        b.WriteString("\n")
+        b.WriteString("credentials are safer in an env file that only you can read.")
+        b.WriteString("\n\n")
+
+        var found []string
+        for _, s := range m.secrets {
+                found = append(found, fmt.Sprintf("%s %s  %s",
+                        warningStyle.Render(lintBadge+s.Kind),
+                        maskSecret(s.Value),
+                        helpStyle.Render("→ $"+s.Name)))
+        }
+        envPath := m.secretEnvPath()
+        command := StripPercentEscapes(m.inputs[2].Value())
+        details := strings.Join(found, "\n") +
+                "\n\n" + detailLabelStyle.Render("Env file: ") + envPath +
+                "\n" + detailLabelStyle.Render("Command:  ") + ReplaceSecrets(command, envPath, m.secrets)
+
+        b.WriteString(lipgloss.NewStyle().
+                Border(lipgloss.NormalBorder()).
+                BorderForeground(lipgloss.Color("240")).
+                Padding(1, 2).
+                Width(80).
+                Render(details))
+        b.WriteString("\n\n")
+
+        buttonStyle := lipgloss.NewStyle().
+                Border(lipgloss.NormalBorder()).
+                BorderForeground(lipgloss.Color("240")).
+                Padding(0, 2).
+                Margin(0, 1)
+        moveStyle, keepStyle := buttonStyle, buttonStyle
+        if m.secretChoice == 0 {
+                moveStyle = moveStyle.BorderForeground(lipgloss.Color("46")).Bold(true)
+        } else {
+                keepStyle = keepStyle.BorderForeground(lipgloss.Color("196")).Bold(true)
+        }
+        b.WriteString(lipgloss.JoinHorizontal(lipgloss.Left,
+                moveStyle.Render("Move to env file"),
+                keepStyle.Render("Save anyway")))
+        b.WriteString("\n\n")
+
+        b.WriteString(helpStyle.Render("Use left/right arrow keys to select • Enter to save • Esc/q to go back to editing"))
+
+        return b.String()
+}
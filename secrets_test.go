@@ -0,0 +1,55 @@
+package main
+
+import (
+        "os"
+        "path/filepath"
+        "testing"
+)
+
+func TestSecretEnvPathAfterReplace(t *testing.T) {
+        t.Setenv("HOME", t.TempDir())
+        for _, job := range []CronJob{
+                {Command: "mysql -pS3cretPass db"},
+                {Command: "PGPASSWORD=hunter22 pg_dump db", LogFile: "pg-dump"},
+        } {
+                envPath := SecretEnvPath(job)
+                job.Command = ReplaceSecrets(job.Command, envPath, FindSecrets(job.Command))
+                if got := SecretEnvPath(job); got != envPath {
+                        t.Errorf("env file moved from %s to %s once %q sourced it", envPath, got, job.Command)
+                }
+        }
+}
+
+func TestWriteSecretEnvFileReplaces(t *testing.T) {
+        envPath := filepath.Join(t.TempDir(), "secrets", "job.env")
+        if err := os.MkdirAll(filepath.Dir(envPath), 0700); err != nil {
+                t.Fatal(err)
+        }
+        if err := os.WriteFile(envPath, []byte("OTHER=keep\nDB_PASSWORD='old'\n"), 0644); err != nil {
+                t.Fatal(err)
+        }
+
+        secrets := []secretMatch{{Name: "DB_PASSWORD", Value: "new"}, {Name: "API_KEY", Value: "abc"}}
+        if err := WriteSecretEnvFile(envPath, secrets); err != nil {
+                t.Fatal(err)
+        }
+        if err := WriteSecretEnvFile(envPath, secrets); err != nil {
+                t.Fatal(err)
+        }
+
+        data, err := os.ReadFile(envPath)
+        if err != nil {
+                t.Fatal(err)
+        }
+        want := "OTHER=keep\nDB_PASSWORD='new'\nAPI_KEY='abc'\n"
+        if string(data) != want {
+                t.Errorf("env file = %q, want %q", data, want)
+        }
+        info, err := os.Stat(envPath)
+        if err != nil {
+                t.Fatal(err)
+        }
+        if info.Mode().Perm() != 0600 {
+                t.Errorf("env file mode = %v, want 0600", info.Mode().Perm())
+        }
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+        "fmt"
+        "regexp"
+        "strings"
+)
+
+// plainWordRegex matches words that sh reads literally without quoting
+var plainWordRegex = regexp.MustCompile(`^[A-Za-z0-9_./:=@+,-]+$`)
+
+// shellQuote quotes a string for sh so it is passed through as one word
+func shellQuote(s string) string {
+        return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellUnquote reverses shellQuote
+func shellUnquote(s string) string {
+        s = strings.TrimSuffix(strings.TrimPrefix(s, "'"), "'")
+        return strings.ReplaceAll(s, `'\''`, "'")
+}
+
+// shellWord quotes a string only if sh would otherwise change it
+func shellWord(s string) string {
+        if plainWordRegex.MatchString(s) {
+                return s
+        }
+        return shellQuote(s)
+}
+
+// splitShellWords splits a command line into words the way sh would, for the
+// quoting tuicron itself writes: single quotes, double quotes and backslashes.
+// Expansions are left as they are.
+func splitShellWords(line string) ([]string, error) {
+        var words []string
+        var word strings.Builder
+        inWord := false
+
+        for i := 0; i < len(line); i++ {
+                c := line[i]
+                switch {
+                case c == ' ' || c == '\t':
+                        if inWord {
+                                words = append(words, word.String())
+                                word.Reset()
+                                inWord = false
+                        }
+
+                case c == '\'':
+                        end := strings.IndexByte(line[i+1:], '\'')
+                        if end < 0 {
+                                return nil, fmt.Errorf("unterminated single quote")
+                        }
+                        word.WriteString(line[i+1 : i+1+end])
+                        i += end + 1
+                        inWord = true
+
+                case c == '"':
+                        i++
+                        for ; i < len(line) && line[i] != '"'; i++ {
+                                if line[i] == '\\' && i+1 < len(line) && strings.IndexByte("\"\\$`", line[i+1]) >= 0 {
+                                        i++
+                                }
+                                word.WriteByte(line[i])
+                        }
+                        if i >= len(line) {
+                                return nil, fmt.Errorf("unterminated double quote")
+                        }
+                        inWord = true
+
+                case c == '\\' && i+1 < len(line):
+                        i++
+                        word.WriteByte(line[i])
+                        inWord = true
+
+                default:
+                        word.WriteByte(c)
+                        inWord = true
+                }
+        }
+        if inWord {
+                words = append(words, word.String())
+        }
+
+        return words, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+        "sort"
+        "strings"
+        "time"
+)
+
+// SortColumn identifies the column the job table is ordered by
+type SortColumn int
+
+const (
+        SortNone SortColumn = iota // Crontab file order
+        SortDescription
+        SortNextRun
+        SortLastRun
+        SortCommand
+        SortStatus
+)
+
+// sortColumnTitles maps sortable columns to their table header
+var sortColumnTitles = map[SortColumn]string{
+        SortDescription: "Description",
+        SortNextRun:     "Next Run",
+        SortLastRun:     "Last Run",
+        SortCommand:     "Command",
+        SortStatus:      "Status",
+}
+
+// statusRank orders job statuses so that problems sort first
+var statusRank = map[string]int{
+        StatusError:    0,
+        StatusTimedOut: 1,
+        StatusRunning:  2,
+        StatusOK:       3,
+        StatusNeverRun: 4,
+        StatusNoLog:    5,
+        StatusDisabled: 6,
+}
+
+// statusOrder returns a status's rank, putting any status statusRank doesn't
+// know after the ones it does rather than among the errors
+func statusOrder(status string) int {
+        if rank, ok := statusRank[status]; ok {
+                return rank
+        }
+        return len(statusRank)
+}
+
+// Next returns the column that follows c when cycling through sort columns
+func (c SortColumn) Next() SortColumn {
+        if c == SortStatus {
+                return SortNone
+        }
+        return c + 1
+}
+
+// String returns a short label for the sort column
+func (c SortColumn) String() string {
+        if title, ok := sortColumnTitles[c]; ok {
+                return title
+        }
+        return "File order"
+}
+
+// compareTimes orders two times, always placing zero times ("Never") last
+func compareTimes(a, b time.Time, descending bool) int {
+        switch {
+        case a.Equal(b):
+                return 0
+        case a.IsZero():
+                return 1
+        case b.IsZero():
+                return -1
+        case a.Before(b) != descending:
+                return -1
+        default:
+                return 1
+        }
+}
+
+// compareJobs compares two jobs on the given column
+func compareJobs(a, b CronJob, column SortColumn, descending bool) int {
+        c := 0
+        switch column {
+        case SortDescription:
+                c = strings.Compare(strings.ToLower(a.Description), strings.ToLower(b.Description))
+        case SortNextRun:
+                return compareTimes(a.NextRun, b.NextRun, descending)
+        case SortLastRun:
+                return compareTimes(a.LastRun, b.LastRun, descending)
+        case SortCommand:
+                c = strings.Compare(StripLoggingFromCommand(a.Command), StripLoggingFromCommand(b.Command))
+        case SortStatus:
+                c = statusOrder(a.LastStatus) - statusOrder(b.LastStatus)
+        }
+        if descending {
+                return -c
+        }
+        return c
+}
+
+// sortJobIndices orders indices into jobs by the given column. The jobs slice
+// itself is left untouched so the crontab keeps its file order, and ties fall
+// back to file order.
+func sortJobIndices(indices []int, jobs []CronJob, column SortColumn, descending bool) {
+        if column == SortNone {
+                return
+        }
+
+        sort.SliceStable(indices, func(i, j int) bool {
+                return compareJobs(jobs[indices[i]], jobs[indices[j]], column, descending) < 0
+        })
+}
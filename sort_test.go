@@ -0,0 +1,21 @@
+package main
+
+import "testing"
+
+func TestSortByStatus(t *testing.T) {
+        statuses := []string{"", StatusDisabled, StatusNoLog, StatusNeverRun, StatusOK, StatusRunning, StatusTimedOut, StatusError}
+        var jobs []CronJob
+        var indices []int
+        for i, status := range statuses {
+                jobs = append(jobs, CronJob{LastStatus: status})
+                indices = append(indices, i)
+        }
+
+        sortJobIndices(indices, jobs, SortStatus, false)
+        want := []string{StatusError, StatusTimedOut, StatusRunning, StatusOK, StatusNeverRun, StatusNoLog, StatusDisabled, ""}
+        for i, index := range indices {
+                if got := jobs[index].LastStatus; got != want[i] {
+                        t.Errorf("position %d has status %q, want %q", i+1, got, want[i])
+                }
+        }
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+        "bufio"
+        "bytes"
+        "errors"
+        "fmt"
+        "io"
+        "os"
+        "path/filepath"
+        "sort"
+        "strings"
+
+        "gopkg.in/yaml.v3"
+)
+
+// specVersion is the version of the spec format tuicron writes and reads
+const specVersion = 1
+
+// Spec is a declarative description of a crontab, kept in version control
+// and applied with `tuicron apply`
+type Spec struct {
+        Version int       `json:"version" yaml:"version"`
+        Jobs    []JobSpec `json:"jobs" yaml:"jobs"`
+}
+
+// JobSpec describes one job in a spec. Jobs are matched to installed ones by
+// their log file name, or by their command when they have none. Env, as in a
+// crontab, also applies to the jobs that follow.
+type JobSpec struct {
+        Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+        Expression  string            `json:"expression" yaml:"expression"`
+        Command     string            `json:"command" yaml:"command"`
+        LogFile     string            `json:"log_file,omitempty" yaml:"log_file,omitempty"`
+        Tags        []string          `json:"tags,omitempty" yaml:"tags,omitempty"`
+        Env         map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+        NoOverlap   bool              `json:"no_overlap,omitempty" yaml:"no_overlap,omitempty"`
+        Timeout     string            `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+        KillAfter   string            `json:"kill_after,omitempty" yaml:"kill_after,omitempty"`
+        Retry       string            `json:"retry,omitempty" yaml:"retry,omitempty"`
+        Notify      string            `json:"notify,omitempty" yaml:"notify,omitempty"`
+        Stdin       string            `json:"stdin,omitempty" yaml:"stdin,omitempty"`
+        Disabled    bool              `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+}
+
+// NewSpec describes the given jobs
+func NewSpec(jobs []CronJob) Spec {
+        spec := Spec{Version: specVersion, Jobs: make([]JobSpec, len(jobs))}
+        for i, job := range jobs {
+                spec.Jobs[i] = jobSpecFromJob(job)
+        }
+        return spec
+}
+
+// jobSpecFromJob describes a job the way a spec holds it
+func jobSpecFromJob(job CronJob) JobSpec {
+        spec := JobSpec{
+                Description: job.Description,
+                Expression:  job.Expression,
+                Command:     job.Command,
+                LogFile:     job.LogFile,
+                Tags:        job.Tags,
+                Env:         envMap(job.Env),
+                NoOverlap:   job.NoOverlap,
+                Retry:       job.Retry.String(),
+                Notify:      job.Notify,
+                Stdin:       job.Stdin,
+                Disabled:    job.Disabled,
+        }
+        if job.Timeout > 0 {
+                spec.Timeout = FormatTimeout(job.Timeout)
+                if job.KillAfter != defaultKillAfter {
+                        spec.KillAfter = FormatTimeout(job.KillAfter)
+                }
+        }
+        return spec
+}
+
+// Job turns the spec into a job, checking it the same way add and edit do
+func (s JobSpec) Job() (CronJob, error) {
+        f := jobFlags{
+                expression:  s.Expression,
+                command:     s.Command,
+                description: s.Description,
+                logFile:     s.LogFile,
+                tags:        strings.Join(s.Tags, ","),
+                timeout:     s.Timeout,
+                killAfter:   s.KillAfter,
+                retry:       s.Retry,
+                notify:      s.Notify,
+                noOverlap:   s.NoOverlap,
+                disabled:    s.Disabled,
+        }
+        set := map[string]bool{}
+        for _, name := range []string{"expr", "command", "desc", "log", "tags", "timeout", "kill-after", "retry", "notify", "no-overlap", "disabled"} {
+                set[name] = true
+        }
+
+        var job CronJob
+        if err := f.apply(&job, set); err != nil {
+                return CronJob{}, err
+        }
+        job.Stdin = s.Stdin
+
+        names := make([]string, 0, len(s.Env))
+        for name := range s.Env {
+                if !assignmentRegex.MatchString(name + "=") {
+                        return CronJob{}, fmt.Errorf("invalid environment variable name %q", name)
+                }
+                names = append(names, name)
+        }
+        sort.Strings(names)
+        for _, name := range names {
+                job.Env = append(job.Env, name+"="+s.Env[name])
+        }
+        return job, nil
+}
+
+// ReadSpec reads a spec from YAML or JSON, rejecting unknown fields so typos
+// don't go unnoticed
+func ReadSpec(data []byte) (Spec, error) {
+        var spec Spec
+        decoder := yaml.NewDecoder(bytes.NewReader(data))
+        decoder.KnownFields(true)
+        if err := decoder.Decode(&spec); err != nil && !errors.Is(err, io.EOF) {
+                return Spec{}, fmt.Errorf("failed to parse spec: %v", err)
+        }
+        if spec.Version != specVersion {
+                return Spec{}, fmt.Errorf("unsupported spec version %d, expected %d", spec.Version, specVersion)
+        }
+        return spec, nil
+}
+
+// planAction is what applying a spec does to one job
+type planAction int
+
+const (
+        planAdd planAction = iota
+        planChange
+        planRemove
+        planKeep
+)
+
+// planStep is one entry of the plan for applying a spec
+type planStep struct {
+        Action  planAction
+        Job     CronJob  // The job as it will be, or as it was for removals
+        Changes []string // Fields that differ, for changes
+}
+
+// specPlan is everything applying a spec would do, and the resulting jobs
+type specPlan struct {
+        Steps []planStep
+        Jobs  []CronJob
+}
+
+// counts returns how many jobs the plan adds, changes and removes
+func (p specPlan) counts() (int, int, int) {
+        var adds, changes, removes int
+        for _, step := range p.Steps {
+                switch step.Action {
+                case planAdd:
+                        adds++
+                case planChange:
+                        changes++
+                case planRemove:
+                        removes++
+                }
+        }
+        return adds, changes, removes
+}
+
+// PlanSpec works out how to turn the installed jobs into the ones in the spec.
+// The resulting crontab lists jobs in spec order.
+func PlanSpec(installed []CronJob, spec Spec) (specPlan, error) {
+        var plan specPlan
+
+        current := map[string]int{}
+        for i, job := range installed {
+                current[JobID(job)] = i
+        }
+
+        // As in a crontab, variables set for one job stay set for the jobs after it
+        var env []string
+
+        seen := map[string]bool{}
+        for n, jobSpec := range spec.Jobs {
+                job, err := jobSpec.Job()
+                if err != nil {
+                        return specPlan{}, fmt.Errorf("job %d: %v", n+1, err)
+                }
+                env = append([]string(nil), env...)
+                for _, assignment := range job.Env {
+                        parts := strings.SplitN(assignment, "=", 2)
+                        env = setEnv(env, parts[0], parts[1])
+                }
+                job.Env = env
+                id := JobID(job)
+                if seen[id] {
+                        return specPlan{}, fmt.Errorf("job %d: another job is also identified as %q, give them different log files", n+1, id)
+                }
+                seen[id] = true
+
+                index, ok := current[id]
+                switch {
+                case !ok:
+                        plan.Steps = append(plan.Steps, planStep{Action: planAdd, Job: job})
+                default:
+                        changes := diffJobSpecs(jobSpecFromJob(installed[index]), jobSpecFromJob(job))
+                        action := planKeep
+                        if len(changes) > 0 {
+                                action = planChange
+                        }
+                        plan.Steps = append(plan.Steps, planStep{Action: action, Job: job, Changes: changes})
+                }
+                plan.Jobs = append(plan.Jobs, job)
+        }
+
+        for _, job := range installed {
+                if !seen[JobID(job)] {
+                        plan.Steps = append(plan.Steps, planStep{Action: planRemove, Job: job})
+                }
+        }
+
+        return plan, nil
+}
+
+// diffJobSpecs lists the fields that differ between two job specs
+func diffJobSpecs(old, new JobSpec) []string {
+        var changes []string
+        diff := func(field, a, b string) {
+                if a != b {
+                        changes = append(changes, fmt.Sprintf("%s: %q → %q", field, a, b))
+                }
+        }
+        diff("description", old.Description, new.Description)
+        diff("expression", old.Expression, new.Expression)
+        diff("command", old.Command, new.Command)
+        diff("log_file", old.LogFile, new.LogFile)
+        diff("tags", strings.Join(old.Tags, ","), strings.Join(new.Tags, ","))
+        diff("env", formatEnvMap(old.Env), formatEnvMap(new.Env))
+        diff("no_overlap", fmt.Sprint(old.NoOverlap), fmt.Sprint(new.NoOverlap))
+        diff("timeout", old.Timeout, new.Timeout)
+        diff("kill_after", old.KillAfter, new.KillAfter)
+        diff("retry", old.Retry, new.Retry)
+        diff("notify", old.Notify, new.Notify)
+        diff("stdin", old.Stdin, new.Stdin)
+        diff("disabled", fmt.Sprint(old.Disabled), fmt.Sprint(new.Disabled))
+        return changes
+}
+
+// formatEnvMap formats environment variables in name order for comparing
+func formatEnvMap(env map[string]string) string {
+        assignments := make([]string, 0, len(env))
+        for name, value := range env {
+                assignments = append(assignments, name+"="+value)
+        }
+        sort.Strings(assignments)
+        return strings.Join(assignments, " ")
+}
+
+// printPlan writes the plan the way `tuicron apply` shows it
+func printPlan(w io.Writer, plan specPlan) {
+        for _, step := range plan.Steps {
+                switch step.Action {
+                case planAdd:
+                        fmt.Fprintf(w, "+ add     %s  %s  %s\n", jobName(step.Job), step.Job.Expression, step.Job.Command)
+                case planChange:
+                        fmt.Fprintf(w, "~ change  %s\n", jobName(step.Job))
+                        for _, change := range step.Changes {
+                                fmt.Fprintf(w, "      %s\n", change)
+                        }
+                case planRemove:
+                        fmt.Fprintf(w, "- remove  %s  %s  %s\n", jobName(step.Job), step.Job.Expression, step.Job.Command)
+                }
+        }
+        adds, changes, removes := plan.counts()
+        fmt.Fprintf(w, "Plan: %d to add, %d to change, %d to remove.\n", adds, changes, removes)
+}
+
+// specFormat picks JSON or YAML from a file name, defaulting to YAML
+func specFormat(path string) string {
+        if strings.EqualFold(filepath.Ext(path), ".json") {
+                return OutputJSON
+        }
+        return OutputYAML
+}
+
+// runExport implements `tuicron export`
+func runExport(args []string) int {
+        flags := newCLIFlags("export")
+        output := flags.String("output", "", "spec format: yaml or json, by default from the file name")
+        flags.StringVar(output, "o", "", "shorthand for --output")
+        path, err := parseJobArgs(flags, args, false)
+        if err != nil {
+                return cliFail("export", flags, err)
+        }
+        if *output == "" {
+                *output = specFormat(path)
+        }
+        if *output != OutputJSON && *output != OutputYAML {
+                return cliFail("export", flags, fmt.Errorf("unknown spec format %q, use yaml or json", *output))
+        }
+
+        jobs, err := loadCLIJobs()
+        if err != nil {
+                return cliFail("export", nil, err)
+        }
+
+        var w io.Writer = os.Stdout
+        toFile := path != "" && path != "-"
+        if toFile {
+                file, err := os.Create(path)
+                if err != nil {
+                        return cliFail("export", nil, err)
+                }
+                defer file.Close()
+                w = file
+        }
+        if err := writeStructured(w, *output, NewSpec(jobs)); err != nil {
+                return cliFail("export", nil, err)
+        }
+        if toFile {
+                fmt.Fprintf(os.Stderr, "Exported %s to %s\n", countNoun(len(jobs), "job"), path)
+        }
+        return 0
+}
+
+// runApply implements `tuicron apply`, which shows what a spec would change
+// and then installs it
+func runApply(args []string) int {
+        flags := newCLIFlags("apply")
+        yes := flags.Bool("yes", false, "apply without asking")
+        flags.BoolVar(yes, "y", false, "shorthand for --yes")
+        dryRun := flags.Bool("dry-run", false, "only show the plan")
+        path, err := parseJobArgs(flags, args, true)
+        if err != nil {
+                return cliFail("apply", flags, err)
+        }
+
+        var data []byte
+        if path == "-" {
+                data, err = io.ReadAll(os.Stdin)
+        } else {
+                data, err = os.ReadFile(path)
+        }
+        if err != nil {
+                return cliFail("apply", nil, err)
+        }
+        spec, err := ReadSpec(data)
+        if err != nil {
+                return cliFail("apply", nil, err)
+        }
+
+        installed, err := loadCLIJobs()
+        if err != nil {
+                return cliFail("apply", nil, err)
+        }
+        plan, err := PlanSpec(installed, spec)
+        if err != nil {
+                return cliFail("apply", nil, err)
+        }
+
+        printPlan(os.Stdout, plan)
+        adds, changes, removes := plan.counts()
+        if adds+changes+removes == 0 {
+                fmt.Println("The crontab already matches the spec.")
+                return 0
+        }
+        if *dryRun {
+                return 0
+        }
+
+        if !*yes {
+                if path == "-" || !isTerminal(os.Stdin) {
+                        return cliFail("apply", nil, fmt.Errorf("not applying without --yes when there is no terminal to ask on"))
+                }
+                fmt.Print("Apply this plan? [y/N] ")
+                answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+                if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+                        fmt.Println("Nothing changed.")
+                        return 1
+                }
+        }
+
+        for i := range plan.Jobs {
+                if err := saveCLIJob(&plan.Jobs[i]); err != nil {
+                        return cliFail("apply", nil, err)
+                }
+        }
+        if err := WriteCrontab(plan.Jobs); err != nil {
+                return cliFail("apply", nil, err)
+        }
+        fmt.Printf("Applied: %d added, %d changed, %d removed.\n", adds, changes, removes)
+        return 0
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+        info, err := f.Stat()
+        return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+        "strings"
+        "testing"
+)
+
+func TestReadSpec(t *testing.T) {
+        yamlSpec := `version: 1
+jobs:
+  - description: Nightly backup
+    expression: "0 2 * * *"
+    command: /usr/local/bin/backup
+    log_file: backup
+    retry: 3, 30s, x2
+    env:
+      PATH: /usr/local/bin:/usr/bin:/bin
+`
+        spec, err := ReadSpec([]byte(yamlSpec))
+        if err != nil {
+                t.Fatalf("ReadSpec(yaml) failed: %v", err)
+        }
+        if len(spec.Jobs) != 1 || spec.Jobs[0].LogFile != "backup" || spec.Jobs[0].Env["PATH"] != "/usr/local/bin:/usr/bin:/bin" {
+                t.Errorf("ReadSpec(yaml) = %+v", spec)
+        }
+
+        jsonSpec := `{"version": 1, "jobs": [{"expression": "*/5 * * * *", "command": "poll", "no_overlap": true}]}`
+        if spec, err := ReadSpec([]byte(jsonSpec)); err != nil {
+                t.Errorf("ReadSpec(json) failed: %v", err)
+        } else if len(spec.Jobs) != 1 || !spec.Jobs[0].NoOverlap {
+                t.Errorf("ReadSpec(json) = %+v", spec)
+        }
+
+        for _, bad := range []string{
+                "version: 1\njobs:\n  - expression: \"0 2 * * *\"\n    command: backup\n    logfile: backup\n",
+                "version: 2\njobs: []\n",
+                "jobs: []\n",
+                "0 2 * * * /usr/local/bin/backup\n",
+        } {
+                if _, err := ReadSpec([]byte(bad)); err == nil {
+                        t.Errorf("ReadSpec(%q) succeeded, want an error", bad)
+                }
+        }
+}
+
+func TestPlanSpec(t *testing.T) {
+        installed := []CronJob{
+                {Description: "Backup", Expression: "0 2 * * *", Command: "/usr/local/bin/backup", LogFile: "backup"},
+                {Description: "Cleanup", Expression: "0 * * * *", Command: "/usr/local/bin/cleanup", LogFile: "cleanup"},
+                {Expression: "*/5 * * * *", Command: "/usr/local/bin/poll"},
+        }
+        spec := Spec{Version: specVersion, Jobs: []JobSpec{
+                {Expression: "*/5 * * * *", Command: "/usr/local/bin/poll"},
+                {Description: "Backup", Expression: "30 2 * * *", Command: "/usr/local/bin/backup", LogFile: "backup", Timeout: "1h"},
+                {Description: "Report", Expression: "0 8 * * 1", Command: "/usr/local/bin/report", LogFile: "report"},
+        }}
+
+        plan, err := PlanSpec(installed, spec)
+        if err != nil {
+                t.Fatalf("PlanSpec failed: %v", err)
+        }
+
+        want := []struct {
+                action  planAction
+                name    string
+                changes string
+        }{
+                {planKeep, "/usr/local/bin/poll", ""},
+                {planChange, "Backup", `expression: "0 2 * * *" → "30 2 * * *"; timeout: "" → "1h"`},
+                {planAdd, "Report", ""},
+                {planRemove, "Cleanup", ""},
+        }
+        if len(plan.Steps) != len(want) {
+                t.Fatalf("plan has %d steps, want %d", len(plan.Steps), len(want))
+        }
+        for i, step := range plan.Steps {
+                changes := strings.Join(step.Changes, "; ")
+                if step.Action != want[i].action || jobName(step.Job) != want[i].name || changes != want[i].changes {
+                        t.Errorf("step %d = %d %q %q, want %d %q %q", i+1, step.Action, jobName(step.Job), changes, want[i].action, want[i].name, want[i].changes)
+                }
+        }
+        if adds, changes, removes := plan.counts(); adds != 1 || changes != 1 || removes != 1 {
+                t.Errorf("counts() = %d, %d, %d, want 1, 1, 1", adds, changes, removes)
+        }
+
+        var order []string
+        for _, job := range plan.Jobs {
+                order = append(order, jobName(job))
+        }
+        if got, want := strings.Join(order, ", "), "/usr/local/bin/poll, Backup, Report"; got != want {
+                t.Errorf("planned jobs = %s, want %s", got, want)
+        }
+}
+
+func TestPlanSpecEnvCarriesOver(t *testing.T) {
+        spec := Spec{Version: specVersion, Jobs: []JobSpec{
+                {Expression: "0 1 * * *", Command: "first", LogFile: "first", Env: map[string]string{"PATH": "/opt/bin:/usr/bin:/bin"}},
+                {Expression: "0 2 * * *", Command: "second", LogFile: "second", Env: map[string]string{"MAILTO": ""}},
+        }}
+        plan, err := PlanSpec(nil, spec)
+        if err != nil {
+                t.Fatalf("PlanSpec failed: %v", err)
+        }
+        if got, want := strings.Join(plan.Jobs[1].Env, " "), "PATH=/opt/bin:/usr/bin:/bin MAILTO="; got != want {
+                t.Errorf("second job env = %q, want %q", got, want)
+        }
+        if got, want := strings.Join(plan.Jobs[0].Env, " "), "PATH=/opt/bin:/usr/bin:/bin"; got != want {
+                t.Errorf("first job env = %q, want %q", got, want)
+        }
+}
+
+func TestPlanSpecRejects(t *testing.T) {
+        tests := []struct {
+                jobs []JobSpec
+                want string
+        }{
+                {[]JobSpec{{Expression: "0 2 * * *", Command: "a", LogFile: "same"}, {Expression: "0 3 * * *", Command: "b", LogFile: "same"}}, "job 2: another job"},
+                {[]JobSpec{{Expression: "0 2 * *", Command: "a"}}, "job 1:"},
+                {[]JobSpec{{Expression: "0 2 * * *", Command: "a", Env: map[string]string{"BAD NAME": "x"}}}, "job 1: invalid environment variable name"},
+        }
+        for _, tt := range tests {
+                _, err := PlanSpec(nil, Spec{Version: specVersion, Jobs: tt.jobs})
+                if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+                        t.Errorf("PlanSpec(%+v) error = %v, want one starting %q", tt.jobs, err, tt.want)
+                }
+        }
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+        "bufio"
+        "errors"
+        "fmt"
+        "os"
+        "path/filepath"
+        "regexp"
+        "strings"
+
+        "github.com/charmbracelet/bubbletea"
+)
+
+// systemCronDir holds the system crontab, cron.d and the run-parts directories
+var systemCronDir = "/etc"
+
+// runPartsDirs are the directories whose scripts cron runs through run-parts,
+// with the schedule assumed when no crontab line says when, as under anacron
+var runPartsDirs = []struct {
+        Name     string
+        Fallback string
+}{
+        {"cron.hourly", "@hourly"},
+        {"cron.daily", "@daily"},
+        {"cron.weekly", "@weekly"},
+        {"cron.monthly", "@monthly"},
+}
+
+// systemFileNameRegex matches the file names cron reads from cron.d and
+// run-parts runs. Others, such as backups left by package managers, are skipped.
+var systemFileNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// systemJobRegex matches a system crontab job: the schedule, the user it runs
+// as and the command
+var systemJobRegex = regexp.MustCompile(`^(@\w+|\S+\s+\S+\s+\S+\s+\S+\s+\S+)\s+(\S+)\s+(.+)$`)
+
+// systemEnvRegex matches a variable assignment, which applies to the jobs
+// after it in the file
+var systemEnvRegex = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`)
+
+// commentTextRegex matches comments that start with a word
+var commentTextRegex = regexp.MustCompile(`^[A-Za-z]`)
+
+// ReadSystemJobs reads the jobs in /etc/crontab and /etc/cron.d, followed by
+// the scripts in the run-parts directories. Files that can't be read are
+// skipped and the first such error is returned along with the other jobs.
+func ReadSystemJobs() ([]CronJob, error) {
+        var jobs []CronJob
+        var firstErr error
+        keep := func(err error) {
+                if firstErr == nil && !errors.Is(err, os.ErrNotExist) {
+                        firstErr = err
+                }
+        }
+
+        files := []string{filepath.Join(systemCronDir, "crontab")}
+        if entries, err := os.ReadDir(filepath.Join(systemCronDir, "cron.d")); err == nil {
+                for _, entry := range entries {
+                        if !entry.IsDir() && systemFileNameRegex.MatchString(entry.Name()) {
+                                files = append(files, filepath.Join(systemCronDir, "cron.d", entry.Name()))
+                        }
+                }
+        }
+        for _, path := range files {
+                data, err := os.ReadFile(path)
+                if err != nil {
+                        keep(err)
+                        continue
+                }
+                jobs = append(jobs, parseSystemCrontab(string(data), path)...)
+        }
+
+        crontabJobs := len(jobs)
+        for _, dir := range runPartsDirs {
+                path := filepath.Join(systemCronDir, dir.Name)
+                entries, err := os.ReadDir(path)
+                if err != nil {
+                        keep(err)
+                        continue
+                }
+
+                // The crontab line that calls run-parts on the directory says when
+                expression := dir.Fallback
+                for _, job := range jobs[:crontabJobs] {
+                        if strings.Contains(job.Command, path) {
+                                expression = job.Expression
+                                break
+                        }
+                }
+
+                for _, entry := range entries {
+                        info, err := entry.Info()
+                        if err != nil || info.IsDir() || info.Mode()&0111 == 0 || !systemFileNameRegex.MatchString(entry.Name()) {
+                                continue
+                        }
+                        job := CronJob{
+                                Description: dir.Name + "/" + entry.Name(),
+                                Expression:  expression,
+                                Command:     filepath.Join(path, entry.Name()),
+                                Source:      SourceSystem,
+                                SourceFile:  path,
+                                User:        "root",
+                        }
+                        setSystemJobState(&job)
+                        jobs = append(jobs, job)
+                }
+        }
+        return jobs, firstErr
+}
+
+// parseSystemCrontab parses a system crontab, where each job names the user
+// it runs as between the schedule and the command
+func parseSystemCrontab(content, path string) []CronJob {
+        var jobs []CronJob
+        var env []string
+        var description string
+        label := strings.TrimPrefix(path, systemCronDir+string(filepath.Separator))
+
+        scanner := bufio.NewScanner(strings.NewReader(content))
+        for scanner.Scan() {
+                line := strings.TrimSpace(scanner.Text())
+                switch {
+                case line == "":
+                        description = ""
+                        continue
+                case strings.HasPrefix(line, "#"):
+                        // As in the user's crontab, the comment right above a job describes
+                        // it, but not the field diagrams and commented out jobs these
+                        // files often start with
+                        comment := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+                        description = ""
+                        if commentTextRegex.MatchString(comment) && !strings.Contains(strings.ToLower(comment), "cron") {
+                                description = comment
+                        }
+                        continue
+                }
+
+                if matches := systemEnvRegex.FindStringSubmatch(line); matches != nil {
+                        env = setEnv(env, matches[1], matches[2])
+                        continue
+                }
+
+                matches := systemJobRegex.FindStringSubmatch(line)
+                if matches == nil {
+                        continue
+                }
+                command, stdin := SplitCronCommand(matches[3])
+                job := CronJob{
+                        Description: description,
+                        Expression:  matches[1],
+                        Command:     command,
+                        Stdin:       stdin,
+                        Env:         append([]string(nil), env...),
+                        Source:      SourceSystem,
+                        SourceFile:  path,
+                        User:        matches[2],
+                }
+                if job.Description == "" {
+                        job.Description = label
+                }
+                setSystemJobState(&job)
+                jobs = append(jobs, job)
+                description = ""
+        }
+        return jobs
+}
+
+// setSystemJobState fills in a system job's next run. @ shorthands become
+// their five fields, as the rest of tuicron expects, except for @reboot. cron
+// doesn't log system jobs anywhere tuicron reads, so there is no last run or
+// status.
+func setSystemJobState(job *CronJob) {
+        if expanded, ok := cronDescriptors[strings.ToLower(job.Expression)]; ok {
+                job.Expression = expanded
+        }
+
+        // System crontabs line their fields up with tabs, and often write
+        // Sunday as 7, on its own or in lists and ranges such as 5-7
+        job.Expression = normalizeSunday(strings.Join(strings.Fields(job.Expression), " "))
+        job.NextRun, _ = GetNextRunTime(job.Expression)
+        job.LastStatus = StatusNoLog
+}
+
+// systemSourceLabel describes where a system job comes from
+func systemSourceLabel(job CronJob) string {
+        return fmt.Sprintf("%s, runs as %s", job.SourceFile, job.User)
+}
+
+// toggleSystemJobs shows or hides the system crontabs and run-parts scripts
+func (m Model) toggleSystemJobs() (tea.Model, tea.Cmd) {
+        m.showSystem = !m.showSystem
+        m.loadJobs()
+        if m.error != "" {
+                return m, nil
+        }
+        if m.showSystem {
+                count := 0
+                for _, job := range m.jobs {
+                        if job.Source == SourceSystem {
+                                count++
+                        }
+                }
+                m.message = fmt.Sprintf("Showing %s from %s, read-only", countNoun(count, "system job"), systemCronDir)
+        } else {
+                m.message = "Hiding system jobs"
+        }
+        return m, nil
+}
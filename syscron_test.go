@@ -0,0 +1,73 @@
+package main
+
+import (
+        "strings"
+        "testing"
+)
+
+// debianCrontab is /etc/crontab as Debian ships it, with a job added that
+// writes Sunday as 7
+const debianCrontab = `# /etc/crontab: system-wide crontab
+# Unlike any other crontab you don't have to run the ` + "`crontab'" + `
+# command to install the new version when you edit this file
+# and files in /etc/cron.d. These files also have username fields,
+# that none of the other crontabs do.
+
+SHELL=/bin/sh
+# You can also override PATH, but by default, newer versions inherit it from the environment
+#PATH=/usr/local/sbin:/usr/local/bin:/sbin:/bin:/usr/sbin:/usr/bin
+
+# Example of job definition:
+# .---------------- minute (0 - 59)
+# |  .------------- hour (0 - 23)
+# |  |  .---------- day of month (1 - 31)
+# |  |  |  .------- month (1 - 12) OR jan,feb,mar,apr ...
+# |  |  |  |  .---- day of week (0 - 6) (Sunday=0 or 7) OR sun,mon,tue,wed,thu,fri,sat
+# |  |  |  |  |
+# *  *  *  *  * user-name command to be executed
+17 *	* * *	root	cd / && run-parts --report /etc/cron.hourly
+25 6	* * *	root	test -x /usr/sbin/anacron || { cd / && run-parts --report /etc/cron.daily; }
+47 6	* * 7	root	test -x /usr/sbin/anacron || { cd / && run-parts --report /etc/cron.weekly; }
+52 6	1 * *	root	test -x /usr/sbin/anacron || { cd / && run-parts --report /etc/cron.monthly; }
+
+# Rotate the archive at the weekend
+30 2	* * 5-7	backup	/usr/local/bin/rotate-archive
+#
+`
+
+func TestParseSystemCrontab(t *testing.T) {
+        jobs := parseSystemCrontab(debianCrontab, systemCronDir+"/crontab")
+
+        want := []struct {
+                expression  string
+                user        string
+                command     string
+                description string
+        }{
+                {"17 * * * *", "root", "cd / && run-parts --report /etc/cron.hourly", "crontab"},
+                {"25 6 * * *", "root", "test -x /usr/sbin/anacron || { cd / && run-parts --report /etc/cron.daily; }", "crontab"},
+                {"47 6 * * 0", "root", "test -x /usr/sbin/anacron || { cd / && run-parts --report /etc/cron.weekly; }", "crontab"},
+                {"52 6 1 * *", "root", "test -x /usr/sbin/anacron || { cd / && run-parts --report /etc/cron.monthly; }", "crontab"},
+                {"30 2 * * 0,5,6", "backup", "/usr/local/bin/rotate-archive", "Rotate the archive at the weekend"},
+        }
+        if len(jobs) != len(want) {
+                t.Fatalf("parsed %d jobs, want %d", len(jobs), len(want))
+        }
+        for i, job := range jobs {
+                w := want[i]
+                if job.Expression != w.expression || job.User != w.user || job.Command != w.command || job.Description != w.description {
+                        t.Errorf("job %d = %q %q %q %q, want %q %q %q %q", i+1,
+                                job.Expression, job.User, job.Command, job.Description,
+                                w.expression, w.user, w.command, w.description)
+                }
+                if job.Source != SourceSystem || job.LastStatus != StatusNoLog {
+                        t.Errorf("job %d has source %q and status %q", i+1, job.Source, job.LastStatus)
+                }
+                if job.NextRun.IsZero() {
+                        t.Errorf("job %d (%s) has no next run", i+1, job.Expression)
+                }
+                if got := strings.Join(job.Env, " "); got != "SHELL=/bin/sh" {
+                        t.Errorf("job %d env = %q, want %q", i+1, got, "SHELL=/bin/sh")
+                }
+        }
+}
@@ -0,0 +1,473 @@
+package main
+
+import (
+        "fmt"
+        "os"
+        "os/exec"
+        "path/filepath"
+        "regexp"
+        "strings"
+
+        "github.com/charmbracelet/bubbletea"
+        "github.com/charmbracelet/lipgloss"
+)
+
+// systemdConversion is a job translated to a systemd user service and timer
+type systemdConversion struct {
+        Job       CronJob
+        Name      string   // Unit name without the .service or .timer suffix
+        Calendars []string // OnCalendar= values, the timer fires when any matches
+        Service   string
+        Timer     string
+        Notes     []string // Settings that couldn't be carried over, or work differently
+}
+
+// systemdDayNames are the weekday names OnCalendar= uses, indexed like cron
+var systemdDayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+// plainUnitWordRegex matches ExecStart= arguments that need no quoting
+var plainUnitWordRegex = regexp.MustCompile(`^[A-Za-z0-9_./:=@+,-]+$`)
+
+// SystemdUnitName returns the unit name a job is converted to
+func SystemdUnitName(job CronJob) string {
+        return "tuicron-" + JobID(job)
+}
+
+// GetSystemdUserDir returns the directory systemd reads user units from
+func GetSystemdUserDir() string {
+        configDir := os.Getenv("XDG_CONFIG_HOME")
+        if configDir == "" {
+                homeDir, _ := os.UserHomeDir()
+                configDir = filepath.Join(homeDir, ".config")
+        }
+        return filepath.Join(configDir, "systemd", "user")
+}
+
+// CronToOnCalendar translates a five-field cron expression to OnCalendar=
+// values. It returns more than one when cron's either-day rule needs it, with
+// a note explaining why.
+func CronToOnCalendar(expr string) ([]string, []string, error) {
+        ast, err := parseCronAST(expr)
+        if err != nil {
+                return nil, nil, err
+        }
+
+        clock := calendarField(ast.Hour, 2) + ":" + calendarField(ast.Minute, 2) + ":00"
+        month := calendarField(ast.Month, 2)
+        days := calendarWeekdays(ast.DayOfWeek)
+
+        // As in cron, a day field only counts as restricted when it doesn't
+        // start with *, and when both are the job runs if either one matches
+        dom := calendarField(ast.DayOfMonth, 2)
+        domStar := ast.DayOfMonth.Ranges[0].Every
+        dowStar := ast.DayOfWeek.Ranges[0].Every
+        if !domStar && !dowStar {
+                if days == "" {
+                        // Every day of the week matches, so the day of month doesn't matter
+                        dom = "*"
+                } else {
+                        return []string{
+                                fmt.Sprintf("*-%s-%s %s", month, dom, clock),
+                                fmt.Sprintf("%s *-%s-* %s", days, month, clock),
+                        }, []string{"Cron runs the job when either the day of month or the day of week matches, so the timer has an OnCalendar= line for each"}, nil
+                }
+        }
+
+        calendar := fmt.Sprintf("*-%s-%s %s", month, dom, clock)
+        if days != "" {
+                calendar = days + " " + calendar
+        }
+        return []string{calendar}, nil, nil
+}
+
+// calendarField renders a cron field in OnCalendar= syntax, where ranges are
+// written a..b and repetitions start/step
+func calendarField(field cronField, width int) string {
+        var items []string
+        for _, r := range field.Ranges {
+                switch {
+                case r.Every && r.Step <= 1:
+                        return "*"
+                case r.Every || r.Open:
+                        items = append(items, fmt.Sprintf("%0*d/%d", width, r.Start, r.Step))
+                case r.Step > 0:
+                        // A stepped range has no OnCalendar= form, so list its values
+                        for v := r.Start; v <= r.End; v += r.Step {
+                                items = append(items, fmt.Sprintf("%0*d", width, v))
+                        }
+                case r.Start != r.End:
+                        items = append(items, fmt.Sprintf("%0*d..%0*d", width, r.Start, width, r.End))
+                default:
+                        items = append(items, fmt.Sprintf("%0*d", width, r.Start))
+                }
+        }
+        return strings.Join(items, ",")
+}
+
+// calendarWeekdays renders the day of week field as OnCalendar= weekday
+// names, or "" when it matches every day
+func calendarWeekdays(field cronField) string {
+        var matches [7]bool
+        for _, r := range field.Ranges {
+                step := r.Step
+                if step == 0 {
+                        step = 1
+                }
+                for v := r.Start; v <= r.End; v += step {
+                        matches[v%7] = true
+                }
+        }
+
+        // Runs of three or more days, in Monday first order, become ranges
+        order := []int{1, 2, 3, 4, 5, 6, 0}
+        var items []string
+        all := true
+        for i := 0; i < len(order); i++ {
+                if !matches[order[i]] {
+                        all = false
+                        continue
+                }
+                end := i
+                for end+1 < len(order) && matches[order[end+1]] {
+                        end++
+                }
+                switch {
+                case end-i >= 2:
+                        items = append(items, systemdDayNames[order[i]]+".."+systemdDayNames[order[end]])
+                case end > i:
+                        items = append(items, systemdDayNames[order[i]], systemdDayNames[order[end]])
+                default:
+                        items = append(items, systemdDayNames[order[i]])
+                }
+                i = end
+        }
+        if all {
+                return ""
+        }
+        return strings.Join(items, ",")
+}
+
+// verifyCalendars checks OnCalendar= values with systemd-analyze, when it is
+// installed
+func verifyCalendars(calendars []string) error {
+        path, err := exec.LookPath("systemd-analyze")
+        if err != nil {
+                return nil
+        }
+        for _, calendar := range calendars {
+                output, err := exec.Command(path, "calendar", calendar).CombinedOutput()
+                if err != nil {
+                        return fmt.Errorf("systemd-analyze rejected OnCalendar=%s: %s", calendar, strings.TrimSpace(string(output)))
+                }
+        }
+        return nil
+}
+
+// ConvertToSystemd builds the service and timer units for a job
+func ConvertToSystemd(job CronJob) (systemdConversion, error) {
+        calendars, notes, err := CronToOnCalendar(job.Expression)
+        if err != nil {
+                return systemdConversion{}, fmt.Errorf("can't translate schedule %q: %v", job.Expression, err)
+        }
+
+        c := systemdConversion{Job: job, Name: SystemdUnitName(job), Notes: notes}
+
+        var env []string
+        shell := "/bin/sh"
+        for _, assignment := range job.Env {
+                name, value, _ := strings.Cut(assignment, "=")
+                value = unquoteEnvValue(value)
+                switch name {
+                case "CRON_TZ":
+                        for i := range calendars {
+                                calendars[i] += " " + value
+                        }
+                        continue
+                case "MAILTO":
+                        c.Notes = append(c.Notes, "systemd doesn't mail output as MAILTO asks, it goes to the log file or the journal instead")
+                        continue
+                case "SHELL":
+                        shell = value
+                }
+                env = append(env, "Environment="+unitQuote(name+"="+value))
+        }
+        c.Calendars = calendars
+
+        if err := verifyCalendars(calendars); err != nil {
+                return systemdConversion{}, err
+        }
+
+        // The runner keeps the log file, timeout, retries and notify command
+        // working as they did under cron. Without it only some of them map to
+        // service settings.
+        var service []string
+        if runner := RunnerPath(); runner != "" {
+                words := []string{unitWord(runner)}
+                for _, arg := range execArgs(job) {
+                        words = append(words, unitWord(arg))
+                }
+                service = append(service, "ExecStart="+strings.Join(words, " "))
+        } else {
+                service = append(service, "ExecStart="+unitWord(shell)+" -c "+unitWord(job.Command))
+                if job.LogFile != "" {
+                        service = append(service, "StandardOutput=append:"+escapeUnitSpecifiers(GetLogFilePath(job.LogFile)))
+                        c.Notes = append(c.Notes, "Output is appended to the log file, but without the start and finish lines tuicron exec writes")
+                }
+                if job.Timeout > 0 {
+                        service = append(service, "TimeoutStartSec="+FormatTimeout(job.Timeout))
+                        if job.KillAfter > 0 {
+                                service = append(service, "TimeoutStopSec="+FormatTimeout(job.KillAfter))
+                        }
+                }
+                if job.Retry.Enabled() {
+                        c.Notes = append(c.Notes, fmt.Sprintf("Retries (%s) need the tuicron runner and were dropped", job.Retry.String()))
+                }
+                if job.Notify != "" {
+                        c.Notes = append(c.Notes, "The notify command needs the tuicron runner and was dropped, consider OnFailure= instead")
+                }
+        }
+        if job.Stdin != "" {
+                for _, line := range strings.Split(job.Stdin, "\n") {
+                        service = append(service, "StandardInputText="+escapeUnitSpecifiers(line))
+                }
+        }
+
+        header := "# Converted from the crontab by tuicron\n# Cron schedule: " + job.Expression + "\n"
+        comments := ""
+        for _, note := range c.Notes {
+                comments += "# Note: " + note + "\n"
+        }
+
+        c.Service = header + comments +
+                "\n[Unit]\nDescription=" + escapeUnitSpecifiers(jobName(job)) + "\n" +
+                "\n[Service]\nType=oneshot\n" +
+                strings.Join(append(env, service...), "\n") + "\n"
+
+        c.Timer = header +
+                "\n[Unit]\nDescription=Schedule for " + escapeUnitSpecifiers(jobName(job)) + "\n" +
+                "\n[Timer]\n"
+        for _, calendar := range calendars {
+                c.Timer += "OnCalendar=" + calendar + "\n"
+        }
+        c.Timer += "\n[Install]\nWantedBy=timers.target\n"
+
+        return c, nil
+}
+
+// unquoteEnvValue removes the quotes cron strips from a variable's value
+func unquoteEnvValue(value string) string {
+        value = strings.TrimSpace(value)
+        if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+                return value[1 : len(value)-1]
+        }
+        return value
+}
+
+// escapeUnitSpecifiers doubles the % that systemd would read as a specifier
+func escapeUnitSpecifiers(s string) string {
+        return strings.ReplaceAll(s, "%", "%%")
+}
+
+// unitQuote double quotes a value for a unit file setting
+func unitQuote(s string) string {
+        s = strings.ReplaceAll(s, `\`, `\\`)
+        s = strings.ReplaceAll(s, `"`, `\"`)
+        return `"` + escapeUnitSpecifiers(s) + `"`
+}
+
+// unitWord quotes an ExecStart= argument if needed. systemd expands $ itself,
+// so a literal one is doubled.
+func unitWord(s string) string {
+        if plainUnitWordRegex.MatchString(s) {
+                return s
+        }
+        return strings.ReplaceAll(unitQuote(s), "$", "$$")
+}
+
+// Write saves the units to the systemd user directory and reloads systemd,
+// when it is running
+func (c systemdConversion) Write() error {
+        dir := GetSystemdUserDir()
+        if err := os.MkdirAll(dir, 0755); err != nil {
+                return fmt.Errorf("failed to create %s: %v", dir, err)
+        }
+        if err := os.WriteFile(filepath.Join(dir, c.Name+".service"), []byte(c.Service), 0644); err != nil {
+                return fmt.Errorf("failed to write service unit: %v", err)
+        }
+        if err := os.WriteFile(filepath.Join(dir, c.Name+".timer"), []byte(c.Timer), 0644); err != nil {
+                return fmt.Errorf("failed to write timer unit: %v", err)
+        }
+
+        if _, err := exec.LookPath("systemctl"); err == nil {
+                exec.Command("systemctl", "--user", "daemon-reload").Run()
+        }
+        return nil
+}
+
+// Start enables the timer so it runs from now on and after logging in again
+func (c systemdConversion) Start() error {
+        if _, err := exec.LookPath("systemctl"); err != nil {
+                return fmt.Errorf("systemctl isn't installed")
+        }
+        output, err := exec.Command("systemctl", "--user", "enable", "--now", c.Name+".timer").CombinedOutput()
+        if err != nil {
+                return fmt.Errorf("systemctl enable failed: %s", strings.TrimSpace(string(output)))
+        }
+        return nil
+}
+
+// StartCommand is the command that enables the timer by hand
+func (c systemdConversion) StartCommand() string {
+        return "systemctl --user enable --now " + c.Name + ".timer"
+}
+
+// applySystemdConversion writes the units and, when switching over, starts
+// the timer and then disables the cron entry. The cron entry is only disabled
+// once the timer is running, so the job never stops running or runs twice.
+func applySystemdConversion(jobs []CronJob, index int, c systemdConversion, switchOver bool) ([]CronJob, string, error) {
+        if err := c.Write(); err != nil {
+                return jobs, "", err
+        }
+        dir := GetSystemdUserDir()
+        if !switchOver {
+                return jobs, fmt.Sprintf("Wrote %s.service and %s.timer to %s. The cron entry still runs the job, when ready run %s and disable it",
+                        c.Name, c.Name, dir, c.StartCommand()), nil
+        }
+
+        if err := c.Start(); err != nil {
+                return jobs, "", fmt.Errorf("wrote the units to %s but couldn't start the timer, so the cron entry was left enabled: %v", dir, err)
+        }
+        jobs = append([]CronJob(nil), jobs...)
+        SetJobDisabled(&jobs[index], true)
+        if err := WriteCrontab(jobs); err != nil {
+                return jobs, "", fmt.Errorf("started %s.timer but couldn't disable the cron entry, disable it before it runs twice: %v", c.Name, err)
+        }
+        return jobs, fmt.Sprintf("Started %s.timer and disabled the cron entry", c.Name), nil
+}
+
+// openSystemd shows the units a job converts to
+func (m Model) openSystemd(index int) (tea.Model, tea.Cmd) {
+        conversion, err := ConvertToSystemd(m.jobs[index])
+        if err != nil {
+                m.error = fmt.Sprintf("Error converting job: %v", err)
+                return m, nil
+        }
+        m.selected = index
+        m.conversion = conversion
+        m.systemdChoice = 0
+        m.mode = ViewSystemd
+        m.error = ""
+        return m, nil
+}
+
+// updateSystemd handles key presses in the systemd conversion dialog
+func (m Model) updateSystemd(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+        switch msg.String() {
+        case "ctrl+c", "esc", "q":
+                m.mode = ViewTable
+                return m, nil
+
+        case "left", "right", "tab":
+                m.systemdChoice = 1 - m.systemdChoice
+                return m, nil
+
+        case "enter":
+                jobs, message, err := applySystemdConversion(m.jobs, m.selected, m.conversion, m.systemdChoice == 1)
+                m.mode = ViewTable
+                if err != nil {
+                        m.error = err.Error()
+                        return m, nil
+                }
+                m.jobs = jobs
+                m.updateTable()
+                m.error = ""
+                m.message = message
+        }
+        return m, nil
+}
+
+// viewSystemd renders the service a job converts to, without the comments
+// repeating the notes, and what happens next
+func (m Model) viewSystemd() string {
+        var b strings.Builder
+        c := m.conversion
+
+        b.WriteString(titleStyle.Render("Convert to systemd Timer"))
+        b.WriteString("\n\n")
+
+        details := detailLabelStyle.Render("Job:      ") + jobName(c.Job) +
+                "\n" + detailLabelStyle.Render("Cron:     ") + c.Job.Expression +
+                "\n" + detailLabelStyle.Render("Calendar: ") + strings.Join(c.Calendars, "\n          ") +
+                "\n" + detailLabelStyle.Render("Units:    ") + filepath.Join(GetSystemdUserDir(), c.Name+".{service,timer}") +
+                "\n\n" + helpStyle.Render(strings.TrimRight(c.Service[strings.Index(c.Service, "[Unit]"):], "\n"))
+        for _, note := range c.Notes {
+                details += "\n\n" + warningStyle.Render(lintBadge+note)
+        }
+
+        b.WriteString(lipgloss.NewStyle().
+                Border(lipgloss.NormalBorder()).
+                BorderForeground(lipgloss.Color("240")).
+                Padding(1, 2).
+                Width(90).
+                Render(details))
+        b.WriteString("\n\n")
+
+        buttonStyle := lipgloss.NewStyle().
+                Border(lipgloss.NormalBorder()).
+                BorderForeground(lipgloss.Color("240")).
+                Padding(0, 2).
+                Margin(0, 1)
+        writeStyle, switchStyle := buttonStyle, buttonStyle
+        if m.systemdChoice == 0 {
+                writeStyle = writeStyle.BorderForeground(lipgloss.Color("46")).Bold(true)
+        } else {
+                switchStyle = switchStyle.BorderForeground(lipgloss.Color("46")).Bold(true)
+        }
+        b.WriteString(lipgloss.JoinHorizontal(lipgloss.Left,
+                writeStyle.Render("Write units, keep cron entry"),
+                switchStyle.Render("Start timer, disable cron entry")))
+        b.WriteString("\n\n")
+
+        b.WriteString(helpStyle.Render("Use left/right arrow keys to select • Enter to convert • Esc/q to cancel"))
+
+        return b.String()
+}
+
+// runSystemd implements `tuicron systemd`
+func runSystemd(args []string) int {
+        flags := newCLIFlags("systemd")
+        switchOver := flags.Bool("disable-cron", false, "start the timer, then disable the cron entry")
+        dryRun := flags.Bool("dry-run", false, "print the units without writing them")
+        ref, err := parseJobArgs(flags, args, true)
+        if err != nil {
+                return cliFail("systemd", flags, err)
+        }
+
+        jobs, err := loadCLIJobs()
+        if err != nil {
+                return cliFail("systemd", nil, err)
+        }
+        index, err := findJob(jobs, ref)
+        if err != nil {
+                return cliFail("systemd", nil, err)
+        }
+        conversion, err := ConvertToSystemd(jobs[index])
+        if err != nil {
+                return cliFail("systemd", nil, err)
+        }
+
+        if *dryRun {
+                fmt.Printf("# %s.service\n%s\n# %s.timer\n%s", conversion.Name, conversion.Service, conversion.Name, conversion.Timer)
+                return 0
+        }
+
+        for _, note := range conversion.Notes {
+                fmt.Fprintf(os.Stderr, "note: %s\n", note)
+        }
+        _, message, err := applySystemdConversion(jobs, index, conversion, *switchOver)
+        if err != nil {
+                return cliFail("systemd", nil, err)
+        }
+        fmt.Println(message)
+        return 0
+}
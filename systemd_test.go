@@ -0,0 +1,40 @@
+package main
+
+import (
+        "strings"
+        "testing"
+)
+
+func TestCronToOnCalendar(t *testing.T) {
+        tests := []struct {
+                expr  string
+                want  string
+                notes int
+        }{
+                {"*/15 9-17 * * 1-5", "Mon..Fri *-*-* 09..17:00/15:00", 0},
+                {"0 9 1 * 1", "*-*-01 09:00:00 | Mon *-*-* 09:00:00", 1},
+                {"0 0 * * *", "*-*-* 00:00:00", 0},
+                {"@daily", "*-*-* 00:00:00", 0},
+                {"30 2 */2 * *", "*-*-01/2 02:30:00", 0},
+                {"0 9-17/2 * * *", "*-*-* 09,11,13,15,17:00:00", 0},
+                {"0 0 1 1 *", "*-01-01 00:00:00", 0},
+                {"0 0 * * 0,6", "Sat,Sun *-*-* 00:00:00", 0},
+                {"0 0 * * 7", "Sun *-*-* 00:00:00", 0},
+                {"0 8 * * 1,3,5", "Mon,Wed,Fri *-*-* 08:00:00", 0},
+                {"0 12 1-7 * 0-6", "*-*-* 12:00:00", 0},
+        }
+        for _, tt := range tests {
+                calendars, notes, err := CronToOnCalendar(tt.expr)
+                if err != nil {
+                        t.Errorf("CronToOnCalendar(%q) failed: %v", tt.expr, err)
+                        continue
+                }
+                if got := strings.Join(calendars, " | "); got != tt.want || len(notes) != tt.notes {
+                        t.Errorf("CronToOnCalendar(%q) = %q with %d notes, want %q with %d", tt.expr, got, len(notes), tt.want, tt.notes)
+                }
+        }
+
+        if _, _, err := CronToOnCalendar("0 0 * *"); err == nil {
+                t.Errorf("CronToOnCalendar(%q) succeeded, want an error", "0 0 * *")
+        }
+}
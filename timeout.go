@@ -0,0 +1,43 @@
+package main
+
+import (
+        "fmt"
+        "strconv"
+        "time"
+)
+
+// StatusTimedOut is shown when the last run was stopped for running too long
+const StatusTimedOut = "Timed out"
+
+// defaultKillAfter is how long a timed out job has to exit after SIGTERM
+// before it is killed
+const defaultKillAfter = 30 * time.Second
+
+// FormatTimeout formats a duration the way timeout(1) accepts it, using the
+// largest whole unit
+func FormatTimeout(d time.Duration) string {
+        switch {
+        case d%time.Hour == 0:
+                return fmt.Sprintf("%dh", d/time.Hour)
+        case d%time.Minute == 0:
+                return fmt.Sprintf("%dm", d/time.Minute)
+        default:
+                return fmt.Sprintf("%ds", d/time.Second)
+        }
+}
+
+// ParseTimeout parses a timeout such as "90s", "30m" or "1h30m". A bare number
+// is taken as seconds.
+func ParseTimeout(value string) (time.Duration, error) {
+        if seconds, err := strconv.Atoi(value); err == nil {
+                value = fmt.Sprintf("%ds", seconds)
+        }
+        d, err := time.ParseDuration(value)
+        if err != nil {
+                return 0, fmt.Errorf("%q is not a duration like 30s, 10m or 1h", value)
+        }
+        if d < time.Second || d%time.Second != 0 {
+                return 0, fmt.Errorf("%q must be a whole number of seconds", value)
+        }
+        return d, nil
+}
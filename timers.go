@@ -0,0 +1,283 @@
+package main
+
+import (
+        "bufio"
+        "fmt"
+        "os/exec"
+        "path/filepath"
+        "strconv"
+        "strings"
+        "time"
+
+        "github.com/charmbracelet/bubbletea"
+)
+
+// systemdTimeLayout is how systemctl show prints timestamps
+const systemdTimeLayout = "Mon 2006-01-02 15:04:05 MST"
+
+// ReadTimers lists the user's systemd timers as jobs. Without systemctl, or
+// without a user manager to talk to, there are simply no timers.
+func ReadTimers() ([]CronJob, error) {
+        if _, err := exec.LookPath("systemctl"); err != nil {
+                return nil, nil
+        }
+        output, err := exec.Command("systemctl", "--user", "list-timers", "--all", "--no-legend", "--plain").Output()
+        if err != nil {
+                return nil, nil
+        }
+
+        var jobs []CronJob
+        scanner := bufio.NewScanner(strings.NewReader(string(output)))
+        for scanner.Scan() {
+                // The dates before them vary in width, but the last two
+                // columns are always the timer and the unit it starts
+                fields := strings.Fields(scanner.Text())
+                if len(fields) < 2 || !strings.HasSuffix(fields[len(fields)-2], ".timer") {
+                        continue
+                }
+                job, err := readTimer(fields[len(fields)-2], fields[len(fields)-1])
+                if err != nil {
+                        return jobs, err
+                }
+                jobs = append(jobs, job)
+        }
+        return jobs, nil
+}
+
+// readTimer builds a job from a timer's unit files and its current state
+func readTimer(timer, service string) (CronJob, error) {
+        timerFile, err := readUnitFile(timer)
+        if err != nil {
+                return CronJob{}, err
+        }
+        serviceFile, err := readUnitFile(service)
+        if err != nil {
+                return CronJob{}, err
+        }
+
+        job := CronJob{
+                Source:      SourceTimer,
+                TimerUnit:   timer,
+                ServiceUnit: service,
+                Description: lastValue(serviceFile["Description"]),
+        }
+        if job.Description == "" {
+                job.Description = lastValue(timerFile["Description"])
+        }
+
+        // Timers can also fire relative to boot or their last run, which
+        // show as the setting itself
+        var schedule []string
+        for _, key := range []string{"OnCalendar", "OnBootSec", "OnStartupSec", "OnActiveSec", "OnUnitActiveSec", "OnUnitInactiveSec"} {
+                for _, value := range timerFile[key] {
+                        if key != "OnCalendar" {
+                                value = key + "=" + value
+                        }
+                        schedule = append(schedule, value)
+                }
+        }
+        job.Expression = strings.Join(schedule, "; ")
+
+        for _, value := range serviceFile["Environment"] {
+                words, err := splitShellWords(value)
+                if err == nil {
+                        job.Env = append(job.Env, words...)
+                }
+        }
+        applyExecStart(&job, lastValue(serviceFile["ExecStart"]))
+
+        timerState := systemctlShow(timer, "ActiveState", "NextElapseUSecRealtime", "LastTriggerUSec")
+        serviceState := systemctlShow(service, "ActiveState", "Result")
+        job.Disabled = timerState["ActiveState"] != "active"
+        job.LastRun = parseSystemdTime(timerState["LastTriggerUSec"])
+        if !job.Disabled {
+                job.NextRun = parseSystemdTime(timerState["NextElapseUSecRealtime"])
+        }
+
+        switch {
+        case serviceState["ActiveState"] == "activating" || serviceState["ActiveState"] == "active":
+                job.LastStatus = StatusRunning
+        case job.Disabled:
+                job.LastStatus = StatusDisabled
+        case job.LogFile != "":
+                job.LastStatus = GetJobStatus(job)
+        case job.LastRun.IsZero():
+                job.LastStatus = StatusNeverRun
+        case serviceState["Result"] == "success":
+                job.LastStatus = StatusOK
+        default:
+                job.LastStatus = StatusError
+        }
+        return job, nil
+}
+
+// applyExecStart fills in a timer job's command from its service's
+// ExecStart=. Services written by `tuicron systemd` run the job through
+// tuicron exec, so its log file and other settings come back too.
+func applyExecStart(job *CronJob, execStart string) {
+        execStart = strings.TrimLeft(execStart, "@-:+!")
+        execStart = strings.NewReplacer("%%", "%", "$$", "$").Replace(execStart)
+        job.Command = execStart
+
+        words, err := splitShellWords(execStart)
+        if err != nil || len(words) < 2 {
+                return
+        }
+        switch filepath.Base(words[0]) {
+        case "tuicron":
+                if words[1] != "exec" {
+                        return
+                }
+                opts, err := parseExecArgs(words[2:])
+                if err != nil {
+                        return
+                }
+                job.Command = opts.Command
+                job.LogFile = opts.LogFile
+                job.NoOverlap = opts.Lock
+                job.Timeout = opts.Timeout
+                job.KillAfter = opts.KillAfter
+                job.Retry = opts.Retry
+                job.Notify = opts.Notify
+        case "sh", "bash", "dash":
+                if len(words) == 3 && words[1] == "-c" {
+                        job.Command = words[2]
+                }
+        }
+}
+
+// readUnitFile reads a unit's settings, including drop-ins, as systemd sees
+// them. Later values come after earlier ones, and an empty value clears the
+// list as it does in systemd.
+func readUnitFile(unit string) (map[string][]string, error) {
+        output, err := exec.Command("systemctl", "--user", "cat", unit).Output()
+        if err != nil {
+                return nil, fmt.Errorf("failed to read %s: %v", unit, err)
+        }
+
+        settings := map[string][]string{}
+        var line string
+        scanner := bufio.NewScanner(strings.NewReader(string(output)))
+        for scanner.Scan() {
+                text := strings.TrimSpace(scanner.Text())
+                if strings.HasSuffix(text, "\\") {
+                        line += strings.TrimSuffix(text, "\\") + " "
+                        continue
+                }
+                line += text
+                if !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, ";") && !strings.HasPrefix(line, "[") {
+                        if key, value, ok := strings.Cut(line, "="); ok {
+                                key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+                                if value == "" {
+                                        settings[key] = nil
+                                } else {
+                                        settings[key] = append(settings[key], value)
+                                }
+                        }
+                }
+                line = ""
+        }
+        return settings, nil
+}
+
+// lastValue returns the last value of a unit setting, or ""
+func lastValue(values []string) string {
+        if len(values) == 0 {
+                return ""
+        }
+        return values[len(values)-1]
+}
+
+// systemctlShow returns some properties of a unit
+func systemctlShow(unit string, properties ...string) map[string]string {
+        state := map[string]string{}
+        output, err := exec.Command("systemctl", "--user", "show", unit, "--property", strings.Join(properties, ",")).Output()
+        if err != nil {
+                return state
+        }
+        for _, line := range strings.Split(string(output), "\n") {
+                if key, value, ok := strings.Cut(line, "="); ok {
+                        state[key] = value
+                }
+        }
+        return state
+}
+
+// parseSystemdTime parses a timestamp from systemctl show, which is empty or
+// "n/a" when there isn't one
+func parseSystemdTime(value string) time.Time {
+        if strings.HasPrefix(value, "@") {
+                if seconds, err := strconv.ParseInt(value[1:], 10, 64); err == nil {
+                        return time.Unix(seconds, 0)
+                }
+        }
+        if t, err := time.ParseInLocation(systemdTimeLayout, value, time.Local); err == nil {
+                return t
+        }
+        return time.Time{}
+}
+
+// SetTimerEnabled starts and enables a timer, or stops and disables it
+func SetTimerEnabled(job CronJob, enabled bool) error {
+        action := "disable"
+        if enabled {
+                action = "enable"
+        }
+        output, err := exec.Command("systemctl", "--user", action, "--now", job.TimerUnit).CombinedOutput()
+        if err != nil {
+                return fmt.Errorf("systemctl %s failed: %s", action, strings.TrimSpace(string(output)))
+        }
+        return nil
+}
+
+// StartTimerNow runs a timer's service straight away, without waiting for it
+func StartTimerNow(job CronJob) error {
+        output, err := exec.Command("systemctl", "--user", "start", "--no-block", job.ServiceUnit).CombinedOutput()
+        if err != nil {
+                return fmt.Errorf("systemctl start failed: %s", strings.TrimSpace(string(output)))
+        }
+        return nil
+}
+
+// toggleTimer enables or disables a systemd timer and reloads its state
+func (m Model) toggleTimer(index int) (tea.Model, tea.Cmd) {
+        job := m.jobs[index]
+        if err := SetTimerEnabled(job, job.Disabled); err != nil {
+                m.error = err.Error()
+                return m, nil
+        }
+
+        updated, err := readTimer(job.TimerUnit, job.ServiceUnit)
+        if err != nil {
+                m.error = err.Error()
+                return m, nil
+        }
+        m.jobs[index] = updated
+        m.updateTable()
+        m.error = ""
+        if updated.Disabled {
+                m.message = fmt.Sprintf("Stopped and disabled %s", job.TimerUnit)
+        } else {
+                m.message = fmt.Sprintf("Enabled and started %s", job.TimerUnit)
+        }
+        return m, nil
+}
+
+// startNow runs the selected systemd timer's service immediately
+func (m Model) startNow(index int) (tea.Model, tea.Cmd) {
+        job := m.jobs[index]
+        if job.Source != SourceTimer {
+                m.error = readOnlyReason(job)
+                if m.error == "" {
+                        m.error = fmt.Sprintf("Only systemd timers start from here, run cron jobs with tuicron run %d", index+1)
+                }
+                return m, nil
+        }
+        if err := StartTimerNow(job); err != nil {
+                m.error = err.Error()
+                return m, nil
+        }
+        m.error = ""
+        m.message = fmt.Sprintf("Started %s, press h for its history", job.ServiceUnit)
+        return m, nil
+}
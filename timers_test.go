@@ -0,0 +1,89 @@
+package main
+
+import (
+        "os"
+        "path/filepath"
+        "strings"
+        "testing"
+        "time"
+)
+
+func TestApplyExecStart(t *testing.T) {
+        tests := []struct {
+                execStart string
+                want      CronJob
+        }{
+                {
+                        "/usr/local/bin/tuicron exec --job backup --log backup --lock --timeout 1h --retry '3, 30s, x2' --notify 'mail -s failed me' -- '/home/user/backup.sh --full'",
+                        CronJob{Command: "/home/user/backup.sh --full", LogFile: "backup", NoOverlap: true, Timeout: time.Hour,
+                                Retry: RetryPolicy{Attempts: 3, Delay: 30 * time.Second, Backoff: 2}, Notify: "mail -s failed me"},
+                },
+                {"/bin/sh -c 'echo 100%% done >> $$HOME/out'", CronJob{Command: "echo 100% done >> $HOME/out"}},
+                {"-/usr/bin/bash -c 'cd /srv && make'", CronJob{Command: "cd /srv && make"}},
+                {"/usr/bin/rsync -a /src /dst", CronJob{Command: "/usr/bin/rsync -a /src /dst"}},
+                {"/usr/local/bin/tuicron list", CronJob{Command: "/usr/local/bin/tuicron list"}},
+                {"/bin/sh -c 'unterminated", CronJob{Command: "/bin/sh -c 'unterminated"}},
+        }
+        for _, tt := range tests {
+                var job CronJob
+                applyExecStart(&job, tt.execStart)
+                if job.Command != tt.want.Command || job.LogFile != tt.want.LogFile || job.NoOverlap != tt.want.NoOverlap ||
+                        job.Timeout != tt.want.Timeout || job.Retry != tt.want.Retry || job.Notify != tt.want.Notify {
+                        t.Errorf("applyExecStart(%q) = %+v, want %+v", tt.execStart, job, tt.want)
+                }
+        }
+}
+
+func TestReadUnitFile(t *testing.T) {
+        // systemctl cat prints the unit and then each drop-in
+        unit := `# /home/user/.config/systemd/user/backup.service
+[Unit]
+Description=Nightly backup
+
+[Service]
+Type=oneshot
+Environment=A=1
+ExecStartPre=/usr/bin/test\
+        -d /srv
+ExecStart=/usr/local/bin/backup \
+        --full \
+        --verbose
+; a comment = with an equals sign
+
+# /home/user/.config/systemd/user/backup.service.d/override.conf
+[Service]
+Environment=B=2
+ExecStart=
+ExecStart=/usr/local/bin/backup --incremental
+`
+        dir := t.TempDir()
+        if err := os.WriteFile(filepath.Join(dir, "unit"), []byte(unit), 0644); err != nil {
+                t.Fatal(err)
+        }
+        script := "#!/bin/sh\nexec cat " + shellQuote(filepath.Join(dir, "unit")) + "\n"
+        if err := os.WriteFile(filepath.Join(dir, "systemctl"), []byte(script), 0755); err != nil {
+                t.Fatal(err)
+        }
+        t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+        settings, err := readUnitFile("backup.service")
+        if err != nil {
+                t.Fatalf("readUnitFile failed: %v", err)
+        }
+        tests := []struct {
+                key  string
+                want string
+        }{
+                {"Description", "Nightly backup"},
+                {"Type", "oneshot"},
+                {"Environment", "A=1 | B=2"},
+                {"ExecStartPre", "/usr/bin/test -d /srv"},
+                {"ExecStart", "/usr/local/bin/backup --incremental"},
+                {"; a comment", ""},
+        }
+        for _, tt := range tests {
+                if got := strings.Join(settings[tt.key], " | "); got != tt.want {
+                        t.Errorf("%s = %q, want %q", tt.key, got, tt.want)
+                }
+        }
+}
@@ -3,6 +3,7 @@ package main
 import (
         "fmt"
         "strings"
+        "time"
 
         "github.com/charmbracelet/bubbles/table"
         "github.com/charmbracelet/bubbles/textinput"
@@ -19,23 +20,54 @@ const (
         ViewHistory
         ViewHelp
         ViewDeleteConfirm
+        ViewBuilder
+        ViewCalendar
+        ViewLoad
+        ViewSecrets
+        ViewImport
+        ViewSystemd
 )
 
 // Model represents the application state
 type Model struct {
-        mode         ViewMode
-        table        table.Model
-        jobs         []CronJob
-        selected     int
-        editing      bool
-        editingJob   CronJob
-        editIndex    int
-        inputs       []textinput.Model
-        activeInput  int
-        history      []LogEntry
-        error        string
-        message      string
-        deleteChoice int // 0 = No (default), 1 = Yes
+        mode           ViewMode
+        table          table.Model
+        jobs           []CronJob
+        selected       int
+        editing        bool
+        editingJob     CronJob
+        editIndex      int
+        inputs         []textinput.Model
+        activeInput    int
+        history        []LogEntry
+        error          string
+        message        string
+        deleteChoice   int // 0 = No (default), 1 = Yes
+        visible        []int // Indices into jobs for each table row
+        search         textinput.Model
+        searching      bool
+        sortColumn     SortColumn
+        sortDesc       bool
+        width          int
+        height         int
+        columns        []int // Indices into jobColumns currently shown
+        showDetails    bool
+        showAltZone    bool // Also show preview run times in TUICRON_TZ
+        builder        scheduleBuilder
+        calendarDate   time.Time // Selected day in the calendar
+        calendarMode   CalendarMode
+        calendarReturn CalendarMode // Layout to go back to from the day timeline
+        load           loadReport
+        issues         [][]LintIssue // Lint issues for each job, indexed like jobs
+        secrets        []secretMatch // Possible secrets in the command being saved
+        secretChoice   int           // 0 = move them to an env file (default), 1 = save anyway
+        secretsChecked bool          // The user has already been asked about secrets
+        importPath     textinput.Model
+        imports        []importCandidate // Jobs read from the import file, nil while asking for it
+        importCursor   int
+        showSystem     bool              // Also list the system crontabs and run-parts scripts
+        conversion     systemdConversion // Units the selected job converts to
+        systemdChoice  int               // 0 = only write the units (default), 1 = also switch over
 }
 
 // Styles
@@ -53,6 +85,13 @@ var (
                 Foreground(lipgloss.Color("196")).
                 Bold(true)
 
+        timedOutStyle = lipgloss.NewStyle().
+                Foreground(lipgloss.Color("205")).
+                Bold(true)
+
+        warningStyle = lipgloss.NewStyle().
+                Foreground(lipgloss.Color("214"))
+
         successStyle = lipgloss.NewStyle().
                 Foreground(lipgloss.Color("46")).
                 Bold(true)
@@ -71,15 +110,8 @@ var (
 
 // NewModel creates a new application model
 func NewModel() Model {
-        // Create table
-        columns := []table.Column{
-                {Title: "Description", Width: 25},
-                {Title: "Cron Expression", Width: 15},
-                {Title: "Next Run", Width: 20},
-                {Title: "Last Run", Width: 20},
-                {Title: "Command", Width: 30},
-        }
-
+        // Create table, sized for the default width until the terminal reports its size
+        columnKeys, columns := tableColumns(defaultWidth, SortNone, false)
         t := table.New(
                 table.WithColumns(columns),
                 table.WithFocused(true),
@@ -92,14 +124,11 @@ func NewModel() Model {
                 BorderForeground(lipgloss.Color("240")).
                 BorderBottom(true).
                 Bold(false)
-        s.Selected = s.Selected.
-                Foreground(lipgloss.Color("229")).
-                Background(lipgloss.Color("57")).
-                Bold(false)
+        s.Selected = selectedRowStyle
         t.SetStyles(s)
 
         // Create text inputs for editing
-        inputs := make([]textinput.Model, 4)
+        inputs := make([]textinput.Model, 9)
         
         // Description input
         inputs[0] = textinput.New()
@@ -110,9 +139,9 @@ func NewModel() Model {
 
         // Cron expression input
         inputs[1] = textinput.New()
-        inputs[1].Placeholder = "0 9 * * *"
-        inputs[1].CharLimit = 50
-        inputs[1].Width = 20
+        inputs[1].Placeholder = "0 9 * * * or a phrase"
+        inputs[1].CharLimit = 100
+        inputs[1].Width = 30
 
         // Command input
         inputs[2] = textinput.New()
@@ -126,11 +155,59 @@ func NewModel() Model {
         inputs[3].CharLimit = 50
         inputs[3].Width = 30
 
+        // Tags input
+        inputs[4] = textinput.New()
+        inputs[4].Placeholder = "backup, nightly"
+        inputs[4].CharLimit = 100
+        inputs[4].Width = 50
+
+        // Timeout input
+        inputs[5] = textinput.New()
+        inputs[5].Placeholder = "none"
+        inputs[5].CharLimit = 20
+        inputs[5].Width = 10
+
+        // Kill after input
+        inputs[6] = textinput.New()
+        inputs[6].Placeholder = FormatTimeout(defaultKillAfter)
+        inputs[6].CharLimit = 20
+        inputs[6].Width = 10
+
+        // Retry policy input
+        inputs[7] = textinput.New()
+        inputs[7].Placeholder = "3, 30s, x2"
+        inputs[7].CharLimit = 30
+        inputs[7].Width = 20
+
+        // Notify command input
+        inputs[8] = textinput.New()
+        inputs[8].Placeholder = "notify-send \"$TUICRON_JOB $TUICRON_STATUS\""
+        inputs[8].CharLimit = 200
+        inputs[8].Width = 50
+
+        // Search input for filtering the table
+        search := textinput.New()
+        search.Prompt = "/"
+        search.Placeholder = "search jobs..."
+        search.CharLimit = 100
+        search.Width = 40
+
+        // Path input for importing jobs from a file
+        importPath := textinput.New()
+        importPath.Placeholder = "~/old-server.crontab"
+        importPath.CharLimit = 300
+        importPath.Width = 60
+
         m := Model{
                 mode:        ViewTable,
                 table:       t,
                 inputs:      inputs,
                 activeInput: 0,
+                search:      search,
+                importPath:  importPath,
+                width:       defaultWidth,
+                columns:     columnKeys,
+                showDetails: true,
         }
 
         // Load cron jobs
@@ -139,6 +216,26 @@ func NewModel() Model {
         return m
 }
 
+// tableColumns returns the columns that fit into width, marking the one the
+// table is sorted by. The first result holds the indices into jobColumns.
+func tableColumns(width int, sortColumn SortColumn, descending bool) ([]int, []table.Column) {
+        keys, columns := layoutColumns(width)
+
+        if title, ok := sortColumnTitles[sortColumn]; ok {
+                arrow := " ▲"
+                if descending {
+                        arrow = " ▼"
+                }
+                for i := range columns {
+                        if columns[i].Title == title {
+                                columns[i].Title += arrow
+                        }
+                }
+        }
+
+        return keys, columns
+}
+
 // loadJobs loads cron jobs from the system
 func (m *Model) loadJobs() {
         jobs, err := ReadCrontab()
@@ -147,26 +244,64 @@ func (m *Model) loadJobs() {
                 return
         }
 
-        // Update last run times from log files
+        // Update last run times and statuses from log files
         for i := range jobs {
                 if jobs[i].LogFile != "" {
                         jobs[i].LastRun = GetLastRunFromLogFile(jobs[i].LogFile)
                 }
+                jobs[i].LastStatus = GetJobStatus(jobs[i])
+        }
+
+        // systemd timers are listed after the crontab, when there are any
+        timers, err := ReadTimers()
+        jobs = append(jobs, timers...)
+
+        // System crontabs come last, when the system scope is on
+        var systemErr error
+        if m.showSystem {
+                var systemJobs []CronJob
+                systemJobs, systemErr = ReadSystemJobs()
+                jobs = append(jobs, systemJobs...)
         }
 
         m.jobs = jobs
         m.updateTable()
         m.error = ""
+        if err != nil {
+                m.error = fmt.Sprintf("Error loading systemd timers: %v", err)
+        } else if systemErr != nil {
+                m.error = fmt.Sprintf("Error loading system jobs: %v", systemErr)
+        }
 }
 
 // updateTable refreshes the table with current job data
 func (m *Model) updateTable() {
-        rows := make([]table.Row, len(m.jobs))
+        previous := m.selectedJobIndex()
+
+        // Work out which jobs pass the search filter, then order them for display
+        query := m.search.Value()
+        m.visible = make([]int, 0, len(m.jobs))
         for i, job := range m.jobs {
+                if _, ok := matchJob(job, query); ok {
+                        m.visible = append(m.visible, i)
+                }
+        }
+        sortJobIndices(m.visible, m.jobs, m.sortColumn, m.sortDesc)
+
+        // Lint every time the table is rebuilt so the badges follow edits
+        m.issues = LintJobs(m.jobs)
+
+        keys, columns := tableColumns(m.tableWidth(), m.sortColumn, m.sortDesc)
+        rows := make([]table.Row, len(m.visible))
+        for i, jobIndex := range m.visible {
+                job := m.jobs[jobIndex]
                 description := job.Description
                 if description == "" {
                         description = "No description"
                 }
+                if len(m.issues[jobIndex]) > 0 {
+                        description = lintBadge + description
+                }
 
                 nextRun := "Never"
                 if !job.NextRun.IsZero() {
@@ -174,7 +309,7 @@ func (m *Model) updateTable() {
                 }
 
                 var lastRun string
-                if job.LogFile == "" {
+                if job.LogFile == "" && job.Source != SourceTimer {
                         lastRun = "-"
                 } else if !job.LastRun.IsZero() {
                         lastRun = job.LastRun.Format("Jan 2, 15:04")
@@ -184,20 +319,51 @@ func (m *Model) updateTable() {
 
                 // Strip logging from command for display
                 command := StripLoggingFromCommand(job.Command)
-                if len(command) > 28 {
-                        command = command[:25] + "..."
-                }
 
-                rows[i] = table.Row{
+                // Cells in jobColumns order, reduced to the columns that fit
+                cells := []string{
                         description,
+                        jobType(job),
                         job.Expression,
                         nextRun,
                         lastRun,
                         command,
+                        job.LastStatus,
                 }
+                row := make(table.Row, len(columns))
+                for n, key := range keys {
+                        row[n] = truncateText(cells[key], columns[n].Width)
+                }
+                rows[i] = row
         }
 
+        // Clear the rows first so they never disagree with the column count
+        m.columns = keys
+        m.table.SetRows(nil)
+        m.table.SetColumns(columns)
         m.table.SetRows(rows)
+
+        // Keep the cursor on the same job after re-sorting, and on a valid
+        // row when the filter shrinks the table
+        for i, jobIndex := range m.visible {
+                if jobIndex == previous {
+                        m.table.SetCursor(i)
+                        return
+                }
+        }
+        if m.table.Cursor() >= len(rows) && len(rows) > 0 {
+                m.table.SetCursor(len(rows) - 1)
+        }
+}
+
+// selectedJobIndex returns the index into m.jobs of the highlighted table row,
+// or -1 if no row is selected
+func (m Model) selectedJobIndex() int {
+        cursor := m.table.Cursor()
+        if cursor < 0 || cursor >= len(m.visible) {
+                return -1
+        }
+        return m.visible[cursor]
 }
 
 // Init implements the tea.Model interface
@@ -222,11 +388,24 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
                         return m.updateHelp(msg)
                 case ViewDeleteConfirm:
                         return m.updateDeleteConfirm(msg)
+                case ViewBuilder:
+                        return m.updateBuilder(msg)
+                case ViewCalendar:
+                        return m.updateCalendar(msg)
+                case ViewLoad:
+                        return m.updateLoad(msg)
+                case ViewSecrets:
+                        return m.updateSecrets(msg)
+                case ViewImport:
+                        return m.updateImport(msg)
+                case ViewSystemd:
+                        return m.updateSystemd(msg)
                 }
 
         case tea.WindowSizeMsg:
-                m.table.SetWidth(msg.Width - 4)
-                m.table.SetHeight(msg.Height - 10)
+                m.width = msg.Width
+                m.height = msg.Height
+                m.resize()
         }
 
         return m, cmd
@@ -236,10 +415,27 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) updateTableView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
         var cmd tea.Cmd
 
+        if m.searching {
+                return m.updateSearch(msg)
+        }
+
         switch msg.String() {
         case "q", "ctrl+c":
                 return m, tea.Quit
 
+        case "/":
+                m.searching = true
+                m.search.Focus()
+                return m, textinput.Blink
+
+        case "esc":
+                // Clear an active filter
+                if m.search.Value() != "" {
+                        m.search.SetValue("")
+                        m.updateTable()
+                }
+                return m, nil
+
         case "n":
                 m.mode = ViewEdit
                 m.editing = false
@@ -249,40 +445,106 @@ func (m Model) updateTableView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
                 return m, textinput.Blink
 
         case "e":
-                if len(m.jobs) > 0 {
+                if index := m.selectedJobIndex(); index >= 0 {
+                        if reason := readOnlyReason(m.jobs[index]); reason != "" {
+                                m.error = reason
+                                return m, nil
+                        }
                         m.mode = ViewEdit
                         m.editing = true
-                        m.selected = m.table.Cursor()
-                        m.editIndex = m.selected
-                        if m.editIndex < len(m.jobs) {
-                                m.editingJob = m.jobs[m.editIndex]
-                                m.populateInputs()
-                        }
+                        m.selected = index
+                        m.editIndex = index
+                        m.editingJob = m.jobs[index]
+                        m.populateInputs()
                 }
                 return m, textinput.Blink
 
         case "h":
-                if len(m.jobs) > 0 {
-                        m.selected = m.table.Cursor()
-                        if m.selected < len(m.jobs) {
-                                m.mode = ViewHistory
-                                m.history = GetJobHistoryFromLogFile(m.jobs[m.selected].LogFile)
+                if index := m.selectedJobIndex(); index >= 0 {
+                        m.selected = index
+                        m.mode = ViewHistory
+                        m.history = GetJobHistoryFromLogFile(m.jobs[index].LogFile)
+                        if job := m.jobs[index]; job.Source == SourceTimer && job.LogFile == "" {
+                                m.history = GetTimerHistory(job.ServiceUnit)
                         }
                 }
                 return m, nil
 
+        case "s":
+                m.sortColumn = m.sortColumn.Next()
+                m.updateTable()
+                m.message = "Sorted by " + m.sortColumn.String()
+                return m, nil
+
+        case "S":
+                if m.sortColumn != SortNone {
+                        m.sortDesc = !m.sortDesc
+                        m.updateTable()
+                }
+                return m, nil
+
+        case "i":
+                m.showDetails = !m.showDetails
+                m.resize()
+                return m, nil
+
+        case "c":
+                return m.openCalendar()
+
+        case "l":
+                return m.openLoad()
+
+        case "I":
+                return m.openImport()
+
+        case "x":
+                if index := m.selectedJobIndex(); index >= 0 {
+                        if m.jobs[index].Source == SourceTimer {
+                                return m.toggleTimer(index)
+                        }
+                        if reason := readOnlyReason(m.jobs[index]); reason != "" {
+                                m.error = reason
+                                return m, nil
+                        }
+                        return m.toggleDisabled(index)
+                }
+                return m, nil
+
+        case "A":
+                return m.toggleSystemJobs()
+
+        case "R":
+                if index := m.selectedJobIndex(); index >= 0 {
+                        return m.startNow(index)
+                }
+                return m, nil
+
+        case "T":
+                if index := m.selectedJobIndex(); index >= 0 {
+                        if reason := readOnlyReason(m.jobs[index]); reason != "" {
+                                m.error = reason
+                                return m, nil
+                        }
+                        return m.openSystemd(index)
+                }
+                return m, nil
+
         case "r":
                 m.loadJobs()
-                m.message = "Refreshed cron jobs"
+                if m.error == "" {
+                        m.message = "Refreshed cron jobs"
+                }
                 return m, nil
 
         case "d":
-                if len(m.jobs) > 0 {
-                        m.selected = m.table.Cursor()
-                        if m.selected < len(m.jobs) {
-                                m.mode = ViewDeleteConfirm
-                                m.deleteChoice = 0 // Default to "No"
+                if index := m.selectedJobIndex(); index >= 0 {
+                        if reason := readOnlyReason(m.jobs[index]); reason != "" {
+                                m.error = reason
+                                return m, nil
                         }
+                        m.selected = index
+                        m.mode = ViewDeleteConfirm
+                        m.deleteChoice = 0 // Default to "No"
                 }
                 return m, nil
         }
@@ -291,6 +553,65 @@ func (m Model) updateTableView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
         return m, cmd
 }
 
+// toggleDisabled enables or disables a job and saves the crontab
+func (m Model) toggleDisabled(index int) (tea.Model, tea.Cmd) {
+        job := m.jobs[index]
+        SetJobDisabled(&job, !job.Disabled)
+
+        jobs := append([]CronJob(nil), m.jobs...)
+        jobs[index] = job
+        if err := WriteCrontab(jobs); err != nil {
+                m.error = fmt.Sprintf("Error saving crontab: %v", err)
+                return m, nil
+        }
+
+        m.jobs = jobs
+        m.updateTable()
+        m.error = ""
+        if job.Disabled {
+                m.message = fmt.Sprintf("Disabled %q, cron will skip it until it is enabled", jobName(job))
+        } else {
+                m.message = fmt.Sprintf("Enabled %q", jobName(job))
+        }
+        return m, nil
+}
+
+// updateSearch handles key presses while the search input is focused
+func (m Model) updateSearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+        var cmd tea.Cmd
+
+        switch msg.String() {
+        case "ctrl+c":
+                return m, tea.Quit
+
+        case "esc":
+                // Cancel the search and show all jobs again
+                m.searching = false
+                m.search.Blur()
+                m.search.SetValue("")
+                m.updateTable()
+                return m, nil
+
+        case "enter":
+                // Keep the filter and hand the keys back to the table
+                m.searching = false
+                m.search.Blur()
+                return m, nil
+
+        case "up", "down", "pgup", "pgdown":
+                m.table, cmd = m.table.Update(msg)
+                return m, cmd
+        }
+
+        previous := m.search.Value()
+        m.search, cmd = m.search.Update(msg)
+        if m.search.Value() != previous {
+                m.updateTable()
+                m.table.SetCursor(0)
+        }
+        return m, cmd
+}
+
 // updateEdit handles key presses in edit view
 func (m Model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
         var cmd tea.Cmd
@@ -332,6 +653,28 @@ func (m Model) updateEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
         case "ctrl+/":
                 m.mode = ViewHelp
                 return m, nil
+
+        case "ctrl+t":
+                m.showAltZone = !m.showAltZone
+                return m, nil
+
+        case "ctrl+b":
+                return m.openBuilder()
+
+        case "ctrl+o":
+                m.editingJob.NoOverlap = !m.editingJob.NoOverlap
+                return m, nil
+
+        case "ctrl+y":
+                // Accept the cron expression converted from an English phrase
+                if looksLikeNaturalLanguage(m.inputs[1].Value()) {
+                        if schedule, err := ParseNaturalSchedule(m.inputs[1].Value()); err == nil {
+                                m.inputs[1].SetValue(schedule.Expression)
+                                m.inputs[1].CursorEnd()
+                                m.error = ""
+                        }
+                }
+                return m, nil
         }
 
         m.inputs[m.activeInput], cmd = m.inputs[m.activeInput].Update(msg)
@@ -378,24 +721,21 @@ func (m Model) updateDeleteConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
                 if m.deleteChoice == 1 {
                         // Delete the job
                         if m.selected >= 0 && m.selected < len(m.jobs) {
-                                // Remove job from slice
-                                m.jobs = append(m.jobs[:m.selected], m.jobs[m.selected+1:]...)
+                                // Remove the job from a copy, m.jobs only changes once it is saved
+                                jobs := append([]CronJob(nil), m.jobs[:m.selected]...)
+                                jobs = append(jobs, m.jobs[m.selected+1:]...)
                                 
                                 // Save updated crontab
-                                if err := WriteCrontab(m.jobs); err != nil {
+                                if err := WriteCrontab(jobs); err != nil {
                                         m.error = fmt.Sprintf("Error saving crontab: %v", err)
                                         m.mode = ViewTable
                                         return m, nil
                                 }
+                                m.jobs = jobs
                                 
+                                // Refreshing the table also keeps the cursor in range
                                 m.updateTable()
                                 m.message = "Job deleted successfully"
-                                
-                                // Adjust cursor if needed
-                                if m.selected >= len(m.jobs) && len(m.jobs) > 0 {
-                                        m.selected = len(m.jobs) - 1
-                                        m.table.SetCursor(m.selected)
-                                }
                         }
                 }
                 m.mode = ViewTable
@@ -411,6 +751,7 @@ func (m Model) saveJob() (tea.Model, tea.Cmd) {
         expression := m.inputs[1].Value()
         command := m.inputs[2].Value()
         logFile := m.inputs[3].Value()
+        tags := ParseTags(m.inputs[4].Value())
 
         if expression == "" {
                 m.error = "Cron expression is required"
@@ -423,12 +764,76 @@ func (m Model) saveJob() (tea.Model, tea.Cmd) {
         }
 
         // Log file is optional - leave empty for no logging
+        if logFile != "" {
+                if err := ValidateLogFileName(logFile); err != nil {
+                        m.error = fmt.Sprintf("Invalid log file name: %v", err)
+                        return m, nil
+                }
+        }
+
+        // % is escaped for cron when the crontab is written, so an escape
+        // typed by hand would end up doubled
+        percentFixed := false
+        if fixed := StripPercentEscapes(command); fixed != command {
+                command = fixed
+                percentFixed = true
+        }
+
+        // Timeout is optional too, the kill grace period only matters with one
+        var timeout, killAfter time.Duration
+        if value := strings.TrimSpace(m.inputs[5].Value()); value != "" {
+                var err error
+                if timeout, err = ParseTimeout(value); err != nil {
+                        m.error = fmt.Sprintf("Invalid timeout: %v", err)
+                        return m, nil
+                }
+        }
+        if value := strings.TrimSpace(m.inputs[6].Value()); value != "" {
+                var err error
+                if killAfter, err = ParseTimeout(value); err != nil {
+                        m.error = fmt.Sprintf("Invalid kill after: %v", err)
+                        return m, nil
+                }
+        }
+        if timeout > 0 && killAfter == 0 {
+                killAfter = defaultKillAfter
+        }
+        if timeout == 0 {
+                killAfter = 0
+        }
+
+        var retry RetryPolicy
+        if value := strings.TrimSpace(m.inputs[7].Value()); value != "" {
+                var err error
+                if retry, err = ParseRetryPolicy(value); err != nil {
+                        m.error = fmt.Sprintf("Invalid retry policy: %v", err)
+                        return m, nil
+                }
+        }
 
         if err := ValidateCronExpression(expression); err != nil {
+                // Phrases have to be confirmed before they replace the expression
+                if looksLikeNaturalLanguage(expression) {
+                        if schedule, err := ParseNaturalSchedule(expression); err == nil {
+                                m.error = fmt.Sprintf("Press ctrl+y to confirm %q as %s, then save", expression, schedule.Expression)
+                                return m, nil
+                        }
+                }
                 m.error = fmt.Sprintf("Invalid cron expression: %v", err)
                 return m, nil
         }
 
+        // Credentials in the crontab leak into backups, offer to move them out
+        if !m.secretsChecked {
+                if secrets := FindSecrets(command); len(secrets) > 0 {
+                        m.secrets = secrets
+                        m.secretChoice = 0
+                        m.mode = ViewSecrets
+                        m.error = ""
+                        return m, nil
+                }
+        }
+
         // Create the job
         nextRun, _ := GetNextRunTime(expression)
         job := CronJob{
@@ -437,6 +842,18 @@ func (m Model) saveJob() (tea.Model, tea.Cmd) {
                 Command:     command,
                 LogFile:     logFile,
                 NextRun:     nextRun,
+                Tags:        tags,
+                Env:         m.editingJob.Env,
+                NoOverlap:   m.editingJob.NoOverlap,
+                Timeout:     timeout,
+                KillAfter:   killAfter,
+                Retry:       retry,
+                Notify:      StripPercentEscapes(strings.TrimSpace(m.inputs[8].Value())),
+                Stdin:       m.editingJob.Stdin,
+                Disabled:    m.editingJob.Disabled,
+        }
+        if job.Disabled {
+                job.NextRun = time.Time{}
         }
 
         // Create log file if specified
@@ -447,22 +864,30 @@ func (m Model) saveJob() (tea.Model, tea.Cmd) {
                 }
         }
 
-        // Add or update job
-        if m.editing && m.editIndex >= 0 && m.editIndex < len(m.jobs) {
-                m.jobs[m.editIndex] = job
+        job.LastRun = GetLastRunFromLogFile(job.LogFile)
+        job.LastStatus = GetJobStatus(job)
+
+        // Add or update job on a copy, m.jobs only changes once it is saved
+        jobs := append([]CronJob(nil), m.jobs...)
+        if m.editing && m.editIndex >= 0 && m.editIndex < len(jobs) {
+                jobs[m.editIndex] = job
         } else {
-                m.jobs = append(m.jobs, job)
+                jobs = append(jobs, job)
         }
 
         // Save to crontab
-        if err := WriteCrontab(m.jobs); err != nil {
+        if err := WriteCrontab(jobs); err != nil {
                 m.error = fmt.Sprintf("Error saving crontab: %v", err)
                 return m, nil
         }
+        m.jobs = jobs
 
         m.mode = ViewTable
         m.updateTable()
         m.message = "Job saved successfully"
+        if percentFixed {
+                m.message += ` (\% saved as %, which tuicron escapes for cron)`
+        }
         m.error = ""
 
         return m, nil
@@ -486,7 +911,18 @@ func (m *Model) populateInputs() {
         m.inputs[1].SetValue(m.editingJob.Expression)
         m.inputs[2].SetValue(m.editingJob.Command)
         m.inputs[3].SetValue(m.editingJob.LogFile)
-        
+        m.inputs[4].SetValue(strings.Join(m.editingJob.Tags, ", "))
+        m.inputs[5].SetValue("")
+        if m.editingJob.Timeout > 0 {
+                m.inputs[5].SetValue(FormatTimeout(m.editingJob.Timeout))
+        }
+        m.inputs[6].SetValue("")
+        if m.editingJob.KillAfter > 0 && m.editingJob.KillAfter != defaultKillAfter {
+                m.inputs[6].SetValue(FormatTimeout(m.editingJob.KillAfter))
+        }
+        m.inputs[7].SetValue(m.editingJob.Retry.String())
+        m.inputs[8].SetValue(m.editingJob.Notify)
+
         m.activeInput = 0
         m.inputs[0].Focus()
         for i := 1; i < len(m.inputs); i++ {
@@ -507,6 +943,18 @@ func (m Model) View() string {
                 return m.viewHelp()
         case ViewDeleteConfirm:
                 return m.viewDeleteConfirm()
+        case ViewBuilder:
+                return m.viewBuilder()
+        case ViewCalendar:
+                return m.viewCalendar()
+        case ViewLoad:
+                return m.viewLoad()
+        case ViewSecrets:
+                return m.viewSecrets()
+        case ViewImport:
+                return m.viewImport()
+        case ViewSystemd:
+                return m.viewSystemd()
         default:
                 return "Unknown view"
         }
@@ -529,21 +977,63 @@ func (m Model) viewTable() string {
                 b.WriteString("\n\n")
         }
 
-        // Center the table
-        tableView := m.table.View()
+        // Center the table, with the detail pane beside or below it
+        tableView := m.highlightTableMatches(m.table.View())
         centeredTable := lipgloss.NewStyle().
-                Width(120).
+                Width(m.tableWidth() - 2).
                 Align(lipgloss.Center).
                 Render(tableView)
-        b.WriteString(baseStyle.Render(centeredTable))
+        tableBox := baseStyle.Render(centeredTable)
+        if m.detailsBeside() {
+                b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tableBox, m.viewDetails(detailPaneWidth)))
+        } else if m.showDetails {
+                b.WriteString(tableBox)
+                b.WriteString("\n")
+                b.WriteString(m.viewDetails(m.width))
+        } else {
+                b.WriteString(tableBox)
+        }
         b.WriteString("\n")
 
+        if total, jobs := lintSummary(m.issues); total > 0 {
+                b.WriteString(warningStyle.Render(fmt.Sprintf("%s%s in %s, see the detail pane or run tuicron lint", lintBadge, countNoun(total, "issue"), countNoun(jobs, "job"))))
+                b.WriteString("\n")
+        }
+
+        // Search bar, and the selected job's matches in fields the table doesn't show
+        if m.searching || m.search.Value() != "" {
+                b.WriteString(m.search.View())
+                b.WriteString(helpStyle.Render(fmt.Sprintf("  (%d of %d jobs)", len(m.visible), len(m.jobs))))
+                b.WriteString("\n")
+                if index := m.selectedJobIndex(); index >= 0 {
+                        matches, _ := matchJob(m.jobs[index], m.search.Value())
+                        for _, match := range matches {
+                                if m.columnShown(match.Field.Label) {
+                                        continue
+                                }
+                                b.WriteString(helpStyle.Render(match.Field.Label + ": "))
+                                b.WriteString(highlightMatch(match))
+                                b.WriteString("\n")
+                        }
+                }
+        }
+
         // Keybindings
         keybindings := []string{
+                "/: search",
                 "n: new job",
                 "e: edit job", 
                 "h: job history",
                 "d: delete job",
+                "x: enable/disable",
+                "R: start timer now",
+                "A: system jobs",
+                "I: import",
+                "T: to systemd",
+                "i: details",
+                "c: calendar",
+                "l: load",
+                "s/S: sort/reverse",
                 "r: refresh",
                 "q: quit",
         }
@@ -598,7 +1088,7 @@ func (m Model) viewEdit() string {
         if m.activeInput == 1 {
                 cronBorderStyle = cronBorderStyle.BorderForeground(lipgloss.Color("86"))
         }
-        cronInput := cronBorderStyle.Width(20).Padding(0, 1).Render(m.inputs[1].View())
+        cronInput := cronBorderStyle.Width(34).Padding(0, 1).Render(m.inputs[1].View())
         
         // Show human-readable description if expression is valid
         cronDesc := ""
@@ -611,7 +1101,28 @@ func (m Model) viewEdit() string {
         
         cronLine := cronInput + cronDescStyle.Render(cronDesc)
         b.WriteString(cronLine)
-        b.WriteString("\n\n")
+        b.WriteString("\n")
+
+        // Offer to convert an English phrase into a cron expression
+        if looksLikeNaturalLanguage(m.inputs[1].Value()) {
+                if schedule, err := ParseNaturalSchedule(m.inputs[1].Value()); err == nil {
+                        b.WriteString(successStyle.Render("→ " + schedule.Expression))
+                        b.WriteString(cronDescStyle.Render(" (" + ParseCronExpression(schedule.Expression) + ")"))
+                        b.WriteString(helpStyle.Render("  ctrl+y: use this expression"))
+                        b.WriteString("\n")
+                        if schedule.Note != "" {
+                                b.WriteString(cronDescStyle.Render("⚠ " + schedule.Note))
+                                b.WriteString("\n")
+                        }
+                } else {
+                        b.WriteString(errorStyle.Render("✗ " + err.Error()))
+                        b.WriteString("\n")
+                }
+        }
+
+        // Upcoming runs and warnings for the expression as it's typed
+        b.WriteString(m.viewRunPreview())
+        b.WriteString("\n")
 
         // Command field
         b.WriteString("Command:")
@@ -626,7 +1137,20 @@ func (m Model) viewEdit() string {
         }
         cmdInput := cmdBorderStyle.Width(80).Padding(0, 1).Render(m.inputs[2].View())
         b.WriteString(cmdInput)
-        b.WriteString("\n\n")
+        b.WriteString("\n")
+
+        // Cron reads a bare % as a newline, so show how the command is written
+        if command := StripPercentEscapes(m.inputs[2].Value()); strings.Contains(command, "%") {
+                b.WriteString(helpStyle.Render("Installed as: "))
+                b.WriteString(successStyle.Render(EscapeCronPercent(command)))
+                b.WriteString(cronDescStyle.Render(" (% is escaped so cron doesn't turn it into a newline)"))
+                b.WriteString("\n")
+        }
+        if m.editingJob.Stdin != "" {
+                b.WriteString(helpStyle.Render(fmt.Sprintf("Input on stdin: %q (from the text after a bare %% in the crontab)", m.editingJob.Stdin)))
+                b.WriteString("\n")
+        }
+        b.WriteString("\n")
 
         // Log file field
         b.WriteString("Log File:")
@@ -644,12 +1168,85 @@ func (m Model) viewEdit() string {
         b.WriteString(logInput + logDesc)
         b.WriteString("\n\n")
 
+        // Tags field
+        b.WriteString("Tags:")
+        b.WriteString("\n")
+        
+        // Style the tags input with border
+        tagsBorderStyle := lipgloss.NewStyle().
+                Border(lipgloss.NormalBorder()).
+                BorderForeground(lipgloss.Color("240"))
+        if m.activeInput == 4 {
+                tagsBorderStyle = tagsBorderStyle.BorderForeground(lipgloss.Color("86"))
+        }
+        tagsInput := tagsBorderStyle.Width(60).Padding(0, 1).Render(m.inputs[4].View())
+        tagsDesc := cronDescStyle.Render(" (comma separated)")
+        b.WriteString(tagsInput + tagsDesc)
+        b.WriteString("\n\n")
+
+        // Options
+        b.WriteString("Options:")
+        b.WriteString("\n")
+        overlap := "[ ]"
+        if m.editingJob.NoOverlap {
+                overlap = successStyle.Render("[x]")
+        }
+        b.WriteString(fmt.Sprintf("%s Prevent overlapping runs", overlap))
+        b.WriteString(cronDescStyle.Render(" (ctrl+o, skips a run while the previous one holds its lock)"))
+        b.WriteString("\n")
+
+        // Style the timeout inputs with borders
+        timeoutBorderStyle := lipgloss.NewStyle().
+                Border(lipgloss.NormalBorder()).
+                BorderForeground(lipgloss.Color("240"))
+        killBorderStyle := timeoutBorderStyle
+        if m.activeInput == 5 {
+                timeoutBorderStyle = timeoutBorderStyle.BorderForeground(lipgloss.Color("86"))
+        }
+        if m.activeInput == 6 {
+                killBorderStyle = killBorderStyle.BorderForeground(lipgloss.Color("86"))
+        }
+        timeoutInput := timeoutBorderStyle.Width(14).Padding(0, 1).Render(m.inputs[5].View())
+        killInput := killBorderStyle.Width(14).Padding(0, 1).Render(m.inputs[6].View())
+        b.WriteString(lipgloss.JoinHorizontal(lipgloss.Center,
+                "Max runtime: ", timeoutInput, "  Kill after: ", killInput,
+                cronDescStyle.Render(" (SIGTERM at the limit, SIGKILL after the grace period)")))
+        b.WriteString("\n")
+
+        retryBorderStyle := lipgloss.NewStyle().
+                Border(lipgloss.NormalBorder()).
+                BorderForeground(lipgloss.Color("240"))
+        if m.activeInput == 7 {
+                retryBorderStyle = retryBorderStyle.BorderForeground(lipgloss.Color("86"))
+        }
+        retryInput := retryBorderStyle.Width(24).Padding(0, 1).Render(m.inputs[7].View())
+        b.WriteString(lipgloss.JoinHorizontal(lipgloss.Center,
+                "Retry:       ", retryInput,
+                cronDescStyle.Render(" (attempts, delay, backoff factor; empty for no retries)")))
+        b.WriteString("\n")
+
+        notifyBorderStyle := lipgloss.NewStyle().
+                Border(lipgloss.NormalBorder()).
+                BorderForeground(lipgloss.Color("240"))
+        if m.activeInput == 8 {
+                notifyBorderStyle = notifyBorderStyle.BorderForeground(lipgloss.Color("86"))
+        }
+        notifyInput := notifyBorderStyle.Width(54).Padding(0, 1).Render(m.inputs[8].View())
+        b.WriteString(lipgloss.JoinHorizontal(lipgloss.Center,
+                "On failure:  ", notifyInput,
+                cronDescStyle.Render(" (command run when a run fails or times out)")))
+        b.WriteString("\n\n")
+
         // Keybindings
         keybindings := []string{
                 "ctrl+s: save",
                 "ctrl+c: cancel", 
                 "tab: next field",
                 "ctrl+/: cron help",
+                "ctrl+b: schedule builder",
+                "ctrl+t: toggle " + previewLocation().String() + " times",
+                "ctrl+y: accept phrase",
+                "ctrl+o: toggle overlap lock",
         }
         b.WriteString(keybindingStyle.Render(strings.Join(keybindings, " • ")))
 
@@ -669,10 +1266,17 @@ func (m Model) viewHistory() string {
         if job.LogFile != "" {
                 b.WriteString(helpStyle.Render(fmt.Sprintf("Log File: ~/.cron_history/%s.log", job.LogFile)))
                 b.WriteString("\n")
+        } else if job.Source == SourceTimer {
+                b.WriteString(helpStyle.Render(fmt.Sprintf("Journal: journalctl --user -u %s", job.ServiceUnit)))
+                b.WriteString("\n")
         }
         b.WriteString("\n")
 
-        if job.LogFile == "" {
+        if job.Source == SourceSystem {
+                b.WriteString(helpStyle.Render(fmt.Sprintf("System job from %s.", systemSourceLabel(job))))
+                b.WriteString("\n")
+                b.WriteString(helpStyle.Render("cron reports system jobs to the system log, see journalctl -u cron or /var/log/syslog."))
+        } else if job.LogFile == "" && job.Source == SourceCrontab {
                 b.WriteString(helpStyle.Render("No log file configured for this job."))
                 b.WriteString("\n")
                 b.WriteString(helpStyle.Render("Edit the job and add a log file name to enable logging."))
@@ -687,7 +1291,11 @@ func (m Model) viewHistory() string {
 
                         // Color code based on content
                         line := entry.Message
-                        if strings.Contains(strings.ToLower(line), "error") {
+                        if strings.Contains(strings.ToLower(line), "job timed out") {
+                                line = timedOutStyle.Render(line)
+                        } else if strings.Contains(strings.ToLower(line), "retrying in") {
+                                line = cronDescStyle.Render(line)
+                        } else if strings.Contains(strings.ToLower(line), "error") || strings.Contains(line, "giving up") || entry.Status == "failed" {
                                 line = errorStyle.Render(line)
                         } else if strings.Contains(strings.ToLower(line), "warning") {
                                 line = cronDescStyle.Render(line)